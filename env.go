@@ -0,0 +1,221 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/drone/drone-plugin-go/plugin"
+	"github.com/thomasf/drone-mvn/mavendeploy"
+)
+
+// stdinParams returns the drone 0.4 plugin parameters passed either after a
+// "--" argument or on stdin, false is returned if there are none.
+func stdinParams() (*plugin.ParamSet, bool) {
+	for _, v := range os.Args {
+		if v == "--" {
+			return plugin.Stdin, true
+		}
+	}
+	stat, err := os.Stdin.Stat()
+	if err != nil || stat.Mode()&os.ModeCharDevice != 0 {
+		return nil, false
+	}
+	// skip leading white space without waiting for the end of the input,
+	// which the drone 0.4 runner doesn't close.
+	r := bufio.NewReader(os.Stdin)
+	for {
+		b, err := r.Peek(1)
+		if err != nil {
+			return nil, false
+		}
+		if !unicode.IsSpace(rune(b[0])) {
+			break
+		}
+		r.ReadByte()
+	}
+	return plugin.NewParamSet(r), true
+}
+
+// loadEnv populates the configuration from the environment variables used
+// by drone 0.5 and later (and compatible runners). The plugin settings are
+// read from PLUGIN_<SETTING> and the build information from DRONE_*.
+func loadEnv(repo *plugin.Repo, build *plugin.Build, workspace *plugin.Workspace, vargs *mavendeploy.Maven) error {
+	repo.Kind = firstEnv("DRONE_REPO_SCM")
+	repo.Owner = firstEnv("DRONE_REPO_OWNER", "DRONE_REPO_NAMESPACE")
+	repo.Name = firstEnv("DRONE_REPO_NAME")
+	repo.FullName = firstEnv("DRONE_REPO")
+	repo.Link = firstEnv("DRONE_REPO_LINK")
+	repo.Clone = firstEnv("DRONE_GIT_HTTP_URL", "DRONE_REMOTE_URL")
+	repo.Branch = firstEnv("DRONE_REPO_BRANCH")
+	repo.IsPrivate = firstEnv("DRONE_REPO_PRIVATE") == "true"
+
+	build.Event = firstEnv("DRONE_BUILD_EVENT")
+	build.Status = firstEnv("DRONE_BUILD_STATUS")
+	build.Deploy = firstEnv("DRONE_DEPLOY_TO")
+	build.Commit = firstEnv("DRONE_COMMIT_SHA", "DRONE_COMMIT")
+	build.Branch = firstEnv("DRONE_COMMIT_BRANCH", "DRONE_BRANCH")
+	build.Ref = firstEnv("DRONE_COMMIT_REF")
+	build.Refspec = firstEnv("DRONE_COMMIT_REFSPEC")
+	build.Message = firstEnv("DRONE_COMMIT_MESSAGE")
+	build.Author = firstEnv("DRONE_COMMIT_AUTHOR")
+	build.Avatar = firstEnv("DRONE_COMMIT_AUTHOR_AVATAR")
+	build.Email = firstEnv("DRONE_COMMIT_AUTHOR_EMAIL")
+	build.Link = firstEnv("DRONE_BUILD_LINK", "DRONE_COMMIT_LINK")
+	if tag := firstEnv("DRONE_TAG"); tag != "" && build.Ref == "" {
+		build.Ref = "refs/tags/" + tag
+	}
+	number, err := intEnv("DRONE_BUILD_NUMBER")
+	if err != nil {
+		return err
+	}
+	build.Number = int(number)
+	for _, v := range []struct {
+		value *int64
+		name  string
+	}{
+		{&build.Created, "DRONE_BUILD_CREATED"},
+		{&build.Started, "DRONE_BUILD_STARTED"},
+		{&build.Finished, "DRONE_BUILD_FINISHED"},
+	} {
+		*v.value, err = intEnv(v.name)
+		if err != nil {
+			return err
+		}
+	}
+
+	workspace.Path = firstEnv("DRONE_WORKSPACE", "CI_WORKSPACE")
+	if workspace.Path == "" {
+		workspace.Path, err = os.Getwd()
+		if err != nil {
+			return err
+		}
+	}
+	workspace.Root = firstEnv("DRONE_WORKSPACE_BASE", "CI_WORKSPACE_BASE")
+
+	return loadSettings(vargs, "PLUGIN_")
+}
+
+// firstEnv returns the value of the first non empty environment variable.
+func firstEnv(names ...string) string {
+	for _, name := range names {
+		if v := os.Getenv(name); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// intEnv returns the integer value of an environment variable, 0 if not
+// set.
+func intEnv(name string) (int64, error) {
+	v := os.Getenv(name)
+	if v == "" {
+		return 0, nil
+	}
+	i, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %v", name, err)
+	}
+	return i, nil
+}
+
+// loadSettings populates v from environment variables named by prefix and
+// the upper case json name of each field, e.g. PLUGIN_GPG_PRIVATE_KEY.
+// String values are used as is, lists of strings may be comma separated and
+// all other values, like the pom settings, are json encoded.
+func loadSettings(v interface{}, prefix string) error {
+	settings := make(map[string]json.RawMessage)
+	for name, t := range jsonFields(reflect.TypeOf(v).Elem()) {
+		key := prefix + strings.ToUpper(name)
+		value, ok := os.LookupEnv(key)
+		if !ok {
+			continue
+		}
+		raw, err := settingJSON(t, value)
+		if err != nil {
+			return fmt.Errorf("%s: %v", key, err)
+		}
+		settings[name] = raw
+	}
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// jsonFields returns the types of the json encoded fields of the struct t by
+// json name, including the fields of embedded structs.
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if f.Anonymous && tag == "" && f.Type.Kind() == reflect.Struct {
+			for name, t := range jsonFields(f.Type) {
+				fields[name] = t
+			}
+			continue
+		}
+		if f.PkgPath != "" || tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = f.Type
+	}
+	return fields
+}
+
+// settingJSON returns the json encoding of an environment variable value for
+// a field of type t.
+func settingJSON(t reflect.Type, value string) (json.RawMessage, error) {
+	switch {
+	case t.Kind() == reflect.String:
+		return json.Marshal(value)
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.String &&
+		!strings.HasPrefix(strings.TrimSpace(value), "["):
+		values := []string{}
+		for _, v := range splitList(value) {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+		return json.Marshal(values)
+	}
+	if !json.Valid([]byte(value)) {
+		return nil, fmt.Errorf("invalid json value %q", value)
+	}
+	return json.RawMessage(value), nil
+}
+
+// splitList splits a comma separated list, commas within braces are kept so
+// that glob patterns such as dist/*.{zip,tar.gz} are a single value.
+func splitList(value string) []string {
+	var values []string
+	depth, last := 0, 0
+	for i, c := range value {
+		switch c {
+		case '{':
+			depth++
+		case '}':
+			if depth > 0 {
+				depth--
+			}
+		case ',':
+			if depth == 0 {
+				values = append(values, value[last:i])
+				last = i + 1
+			}
+		}
+	}
+	return append(values, value[last:])
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/drone/drone-plugin-go/plugin"
+	"github.com/thomasf/drone-mvn/mavendeploy"
+)
+
+func TestLoadEnv(t *testing.T) {
+	for k, v := range map[string]string{
+		"DRONE_REPO_OWNER":      "foo",
+		"DRONE_REPO_NAME":       "bar",
+		"DRONE_REPO":            "foo/bar",
+		"DRONE_BUILD_NUMBER":    "22",
+		"DRONE_BUILD_EVENT":     "tag",
+		"DRONE_BUILD_STARTED":   "1421029603",
+		"DRONE_COMMIT_SHA":      "9f2849d5",
+		"DRONE_COMMIT_BRANCH":   "master",
+		"DRONE_TAG":             "v1.2.3",
+		"DRONE_WORKSPACE":       "/drone/src",
+		"PLUGIN_USERNAME":       "someuser",
+		"PLUGIN_PASSWORD":       "some,password",
+		"PLUGIN_URL":            "https://repo.example.com/releases",
+		"PLUGIN_GROUP":          "com.test.env",
+		"PLUGIN_SOURCE":         "dist/*.{zip,tar.gz}, modules/**/*.jar",
+		"PLUGIN_DRY_RUN":        "true",
+		"PLUGIN_CHECKSUMS":      "sha1, sha256",
+		"PLUGIN_GPG_PASSPHRASE": "secret",
+		"PLUGIN_POM":            `{"name":"app","licenses":[{"name":"MIT"}]}`,
+	} {
+		t.Setenv(k, v)
+	}
+	var (
+		repo      plugin.Repo
+		build     plugin.Build
+		workspace plugin.Workspace
+		vargs     mavendeploy.Maven
+	)
+	err := loadEnv(&repo, &build, &workspace, &vargs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repo.Owner != "foo" || repo.Name != "bar" || repo.FullName != "foo/bar" {
+		t.Errorf("unexpected repo: %+v", repo)
+	}
+	if build.Number != 22 || build.Event != "tag" || build.Started != 1421029603 ||
+		build.Commit != "9f2849d5" || build.Branch != "master" || build.Ref != "refs/tags/v1.2.3" {
+		t.Errorf("unexpected build: %+v", build)
+	}
+	if workspace.Path != "/drone/src" {
+		t.Errorf("unexpected workspace: %+v", workspace)
+	}
+	expected := mavendeploy.Maven{
+		Repository: mavendeploy.Repository{
+			Username: "someuser",
+			Password: "some,password",
+			URL:      "https://repo.example.com/releases",
+		},
+		Artifact: mavendeploy.Artifact{
+			GroupID: "com.test.env",
+		},
+		GPG: mavendeploy.GPG{
+			Passphrase: "secret",
+		},
+		Args: mavendeploy.Args{
+			Source:    mavendeploy.Patterns{"dist/*.{zip,tar.gz}", "modules/**/*.jar"},
+			DryRun:    true,
+			Checksums: []string{"sha1", "sha256"},
+		},
+		POM: mavendeploy.POM{
+			Name:     "app",
+			Licenses: []mavendeploy.License{{Name: "MIT"}},
+		},
+	}
+	if !reflect.DeepEqual(vargs, expected) {
+		t.Errorf("unexpected vargs:\n%+v\nexpected:\n%+v", vargs, expected)
+	}
+}
+
+func TestLoadEnvInvalid(t *testing.T) {
+	t.Setenv("PLUGIN_DRY_RUN", "yes")
+	var vargs mavendeploy.Maven
+	err := loadEnv(&plugin.Repo{}, &plugin.Build{}, &plugin.Workspace{}, &vargs)
+	if err == nil || !strings.Contains(err.Error(), "PLUGIN_DRY_RUN") {
+		t.Fatalf("expected PLUGIN_DRY_RUN error, got %v", err)
+	}
+}
+
+func TestPluginEnv(t *testing.T) {
+	if os.Getenv("__TEST_SUBCMD") == "1" {
+		main()
+		return
+	}
+	tmpdir, err := ioutil.TempDir("", "drone-mvn-main-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpdir)
+	cmd := exec.Command(os.Args[0], "-test.run=^TestPluginEnv$")
+	cmd.Env = append(os.Environ(),
+		"__TEST_SUBCMD=1",
+		"DRONE_WORKSPACE=mavendeploy/",
+		"PLUGIN_USERNAME=someuser",
+		"PLUGIN_PASSWORD=somepassword",
+		"PLUGIN_URL=file://"+tmpdir,
+		"PLUGIN_GROUP=com.alkasir.test",
+		"PLUGIN_ARTIFACT=release",
+		"PLUGIN_VERSION=1.2.3",
+		"PLUGIN_SOURCE=test-data/single/release.zip",
+		"PLUGIN_BACKEND=native",
+	)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("%v: %s", err, output)
+	}
+	_, err = os.Stat(tmpdir + "/com/alkasir/test/release/1.2.3/release-1.2.3.zip")
+	if err != nil {
+		t.Fatalf("%v: %s", err, output)
+	}
+}
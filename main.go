@@ -8,6 +8,10 @@ import (
 	"github.com/thomasf/drone-mvn/mavendeploy"
 )
 
+// dryRun forces the dry_run option, useful for previewing what a plugin
+// configuration piped to stdin would deploy.
+var dryRun = flag.Bool("dry-run", false, "print what would be deployed without deploying anything")
+
 // testExpressions allows for quickly testing source/regexp patterns against
 // files via the command line.
 func testExpressions() {
@@ -25,7 +29,7 @@ func testExpressions() {
 			},
 			Args: mavendeploy.Args{
 				Debug:  true,
-				Source: source,
+				Source: mavendeploy.Patterns{source},
 				Regexp: regexp,
 			},
 		}
@@ -45,13 +49,27 @@ func main() {
 	build := plugin.Build{}
 	vargs := mavendeploy.Maven{}
 
-	plugin.Param("repo", &repo)
-	plugin.Param("build", &build)
-	plugin.Param("workspace", &workspace)
-	plugin.Param("vargs", &vargs)
-	plugin.MustParse()
+	if params, ok := stdinParams(); ok {
+		params.Param("repo", &repo)
+		params.Param("build", &build)
+		params.Param("workspace", &workspace)
+		params.Param("vargs", &vargs)
+		err := params.Parse()
+		if err != nil {
+			panic(err)
+		}
+	} else {
+		err := loadEnv(&repo, &build, &workspace, &vargs)
+		if err != nil {
+			panic(err)
+		}
+	}
 
 	vargs.WorkspacePath(workspace.Path)
+	vargs.BuildInfo(build, repo)
+	if *dryRun {
+		vargs.Args.DryRun = true
+	}
 
 	err := vargs.Publish()
 	if err != nil {
@@ -1,10 +1,12 @@
 package main
 
 import (
+	"bytes"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"os/exec"
+	"strings"
 	"sync"
 	"testing"
 	"text/template"
@@ -41,7 +43,8 @@ const testTemplate1 = `{
         "artifact": "Dockerfile",
         "packaging": "Dockerfile",
         "source": "test-data/multiple-matched/app*",
-        "regexp": "(?P<artifact>app-[^-]*)-(?P<classifier>[^-]*-[^-]*)-(?P<version>.*).(?P<extension>tar.gz|zip|readme)"
+        "regexp": "(?P<artifact>app-[^-]*)-(?P<classifier>[^-]*-[^-]*)-(?P<version>.*).(?P<extension>tar.gz|zip|readme)",
+        "dry_run": {{.DryRun}}
     }
 }`
 
@@ -50,7 +53,7 @@ func TestPlugin(t *testing.T) {
 		main()
 		return
 	}
-	cmd := exec.Command(os.Args[0], "-test.run=TestPlugin")
+	cmd := exec.Command(os.Args[0], "-test.run=^TestPlugin$")
 	env := append(os.Environ(), "__TEST_SUBCMD=1")
 	cmd.Env = env
 	stdin, err := cmd.StdinPipe()
@@ -78,9 +81,57 @@ func TestPlugin(t *testing.T) {
 		os.RemoveAll(tmpdir)
 	}()
 	URL := fmt.Sprintf("file://%s", tmpdir)
-	err = tpl.Execute(stdin, &struct{ URL string }{URL: URL})
+	err = tpl.Execute(stdin, &struct {
+		URL    string
+		DryRun bool
+	}{URL: URL})
 	if err != nil {
 		t.Fatal(err)
 	}
 	wg.Wait()
 }
+
+func TestPluginDryRun(t *testing.T) {
+	if os.Getenv("__TEST_SUBCMD") == "1" {
+		main()
+		return
+	}
+	tmpdir, err := ioutil.TempDir("", "drone-mvn-main-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpdir)
+	var stdin bytes.Buffer
+	tpl := template.Must(template.New("template").Parse(testTemplate1))
+	err = tpl.Execute(&stdin, &struct {
+		URL    string
+		DryRun bool
+	}{URL: "file://" + tmpdir, DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command(os.Args[0], "-test.run=^TestPluginDryRun$")
+	cmd.Env = append(os.Environ(), "__TEST_SUBCMD=1")
+	cmd.Stdin = &stdin
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("%v: %s", err, output)
+	}
+	for _, v := range []string{
+		"test-data/multiple-matched/app-gui-darwin-amd64-0.1.4.zip",
+		tmpdir + "/com/alkasir/test/app-gui/0.1.4/app-gui-0.1.4-darwin-amd64.zip",
+		tmpdir + "/com/alkasir/test/app-gui/0.1.4/app-gui-0.1.4.pom.sha1",
+		tmpdir + "/com/alkasir/test/app-gui/maven-metadata.xml",
+	} {
+		if !strings.Contains(string(output), v) {
+			t.Errorf("expected %s in output:\n%s", v, output)
+		}
+	}
+	files, err := ioutil.ReadDir(tmpdir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) > 0 {
+		t.Fatalf("expected no files to be deployed, got %v", files)
+	}
+}
@@ -0,0 +1,180 @@
+package mavendeploy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Artifactory configures publishing an Artifactory build-info document,
+// which links the deployed files to the build, after a successful deploy.
+type Artifactory struct {
+	BuildInfo bool   `json:"build_info"` // publish build info
+	URL       string `json:"url"`        // artifactory url, e.g. https://example.com/artifactory
+	BuildName string `json:"build_name"` // build name, default the repository full name
+}
+
+// buildInfoTimeLayout is the timestamp format of build-info documents.
+const buildInfoTimeLayout = "2006-01-02T15:04:05.000-0700"
+
+// ArtifactoryBuildInfo is an Artifactory build-info document.
+type ArtifactoryBuildInfo struct {
+	Version     string                       `json:"version"`
+	Name        string                       `json:"name"`
+	Number      string                       `json:"number"`
+	Started     string                       `json:"started"`
+	URL         string                       `json:"url,omitempty"`
+	Agent       ArtifactoryBuildInfoAgent    `json:"agent"`
+	BuildAgent  ArtifactoryBuildInfoAgent    `json:"buildAgent"`
+	VCSRevision string                       `json:"vcsRevision,omitempty"`
+	VCSURL      string                       `json:"vcsUrl,omitempty"`
+	VCS         []ArtifactoryBuildInfoVCS    `json:"vcs,omitempty"`
+	Modules     []ArtifactoryBuildInfoModule `json:"modules"`
+}
+
+// ArtifactoryBuildInfoAgent is the CI server or build tool of a build.
+type ArtifactoryBuildInfoAgent struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+// ArtifactoryBuildInfoVCS is the source revision of a build.
+type ArtifactoryBuildInfoVCS struct {
+	Revision string `json:"revision"`
+	URL      string `json:"url,omitempty"`
+	Branch   string `json:"branch,omitempty"`
+	Message  string `json:"message,omitempty"`
+}
+
+// ArtifactoryBuildInfoModule is a groupID:artifactID:version and its files.
+type ArtifactoryBuildInfoModule struct {
+	ID        string                         `json:"id"`
+	Artifacts []ArtifactoryBuildInfoArtifact `json:"artifacts"`
+}
+
+// ArtifactoryBuildInfoArtifact is a deployed file.
+type ArtifactoryBuildInfoArtifact struct {
+	Type   string `json:"type"`
+	Name   string `json:"name"`
+	MD5    string `json:"md5,omitempty"`
+	SHA1   string `json:"sha1,omitempty"`
+	SHA256 string `json:"sha256,omitempty"`
+}
+
+func (a Artifactory) validate() error {
+	if a.BuildInfo && a.URL == "" {
+		return fmt.Errorf("artifactory url is %s", errRequiredValue)
+	}
+	return nil
+}
+
+// buildName returns the configured build name or the repository full name.
+func (mvn *Maven) buildName() string {
+	switch {
+	case mvn.Artifactory.BuildName != "":
+		return mvn.Artifactory.BuildName
+	case mvn.repo.FullName != "":
+		return mvn.repo.FullName
+	case mvn.repo.Owner != "" && mvn.repo.Name != "":
+		return mvn.repo.Owner + "/" + mvn.repo.Name
+	}
+	return ""
+}
+
+// buildInfo returns the build-info document of the deployed artifacts with
+// a module for each artifact group. The checksums are the configured ones
+// which Artifactory knows about.
+func (mvn *Maven) buildInfo() (*ArtifactoryBuildInfo, error) {
+	r, err := mvn.report(true)
+	if err != nil {
+		return nil, err
+	}
+	started := mvn.now()
+	if mvn.build.Started != 0 {
+		started = time.Unix(mvn.build.Started, 0)
+	}
+	b := &ArtifactoryBuildInfo{
+		Version:    "1.0.1",
+		Name:       mvn.buildName(),
+		Number:     strconv.Itoa(mvn.build.Number),
+		Started:    started.Format(buildInfoTimeLayout),
+		URL:        mvn.build.Link,
+		Agent:      ArtifactoryBuildInfoAgent{Name: "drone"},
+		BuildAgent: ArtifactoryBuildInfoAgent{Name: "drone-mvn"},
+	}
+	if mvn.build.Commit != "" {
+		b.VCSRevision = mvn.build.Commit
+		b.VCSURL = mvn.repo.Clone
+		b.VCS = []ArtifactoryBuildInfoVCS{{
+			Revision: mvn.build.Commit,
+			URL:      mvn.repo.Clone,
+			Branch:   mvn.build.Branch,
+			Message:  mvn.build.Message,
+		}}
+	}
+	modules := make(map[string]int)
+	for _, e := range r.Artifacts {
+		id := fmt.Sprintf("%s:%s:%s", e.GroupID, e.ArtifactID, e.Version)
+		i, ok := modules[id]
+		if !ok {
+			i = len(b.Modules)
+			modules[id] = i
+			b.Modules = append(b.Modules, ArtifactoryBuildInfoModule{ID: id})
+		}
+		b.Modules[i].Artifacts = append(b.Modules[i].Artifacts, ArtifactoryBuildInfoArtifact{
+			Type:   e.Extension,
+			Name:   path.Base(e.URL),
+			MD5:    e.Checksums["md5"],
+			SHA1:   e.Checksums["sha1"],
+			SHA256: e.Checksums["sha256"],
+		})
+	}
+	return b, nil
+}
+
+// publishBuildInfo uploads the build-info document to the Artifactory build
+// API.
+func (mvn *Maven) publishBuildInfo() error {
+	b, err := mvn.buildInfo()
+	if err != nil {
+		return err
+	}
+	if b.Name == "" {
+		return fmt.Errorf("artifactory build_name is %s", errRequiredValue)
+	}
+	data, err := json.Marshal(b)
+	if err != nil {
+		return err
+	}
+	u := strings.TrimSuffix(mvn.Artifactory.URL, "/") + "/api/build"
+	if mvn.Args.DryRun {
+		mvn.infof("dry run, not publishing build info %s #%s to %s", b.Name, b.Number, u)
+		return nil
+	}
+	mvn.infof("PUT %s (build info %s #%s)", u, b.Name, b.Number)
+	return mvn.retry("build info", func() error {
+		req, err := http.NewRequest("PUT", u, bytes.NewReader(data))
+		if err != nil {
+			return err
+		}
+		req.SetBasicAuth(mvn.Repository.Username, mvn.Repository.Password)
+		req.Header.Set("Content-Type", "application/json")
+		res, err := mvn.httpClient().Do(req)
+		if err != nil {
+			return err
+		}
+		defer res.Body.Close()
+		io.Copy(ioutil.Discard, res.Body)
+		if res.StatusCode < 200 || res.StatusCode > 299 {
+			return newHTTPError(req, res)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,109 @@
+package mavendeploy
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/drone/drone-plugin-go/plugin"
+)
+
+func TestArtifactoryBuildInfo(t *testing.T) {
+	repo := newFakeRepo()
+	var buildInfo *ArtifactoryBuildInfo
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/artifactory/api/build" {
+			repo.ServeHTTP(w, r)
+			return
+		}
+		if u, p, ok := r.BasicAuth(); r.Method != "PUT" || !ok || u != "u" || p != "p" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		buildInfo = &ArtifactoryBuildInfo{}
+		err := json.NewDecoder(r.Body).Decode(buildInfo)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	mvn := &Maven{
+		Repository: Repository{
+			Username: "u",
+			Password: "p",
+			URL:      server.URL + "/artifactory/libs-release",
+		},
+		Artifact: Artifact{
+			GroupID:    "com.test.buildinfo",
+			ArtifactID: "release",
+			Version:    "1.2.3",
+		},
+		Args: Args{
+			Source:  Patterns{"single/release.zip"},
+			Backend: BackendNative,
+		},
+		Artifactory: Artifactory{
+			BuildInfo: true,
+			URL:       server.URL + "/artifactory/",
+		},
+		workspacePath: "test-data/",
+		quiet:         true,
+	}
+	mvn.BuildInfo(plugin.Build{
+		Number:  22,
+		Commit:  "9f2849d5a1e2",
+		Branch:  "master",
+		Started: 1446292800,
+	}, plugin.Repo{
+		Owner:    "foo",
+		Name:     "bar",
+		FullName: "foo/bar",
+		Clone:    "https://github.com/foo/bar.git",
+	})
+	err := mvn.Publish()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buildInfo == nil {
+		t.Fatal("no build info published")
+	}
+	if buildInfo.Name != "foo/bar" || buildInfo.Number != "22" || buildInfo.VCSRevision != "9f2849d5a1e2" ||
+		buildInfo.VCSURL != "https://github.com/foo/bar.git" {
+		t.Fatalf("unexpected build info %+v", buildInfo)
+	}
+	if buildInfo.Started[:10] != "2015-10-31" {
+		t.Fatalf("unexpected started %s", buildInfo.Started)
+	}
+	pom, err := mvn.projects["com.test.buildinfo:release:1.2.3"].Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, pomSums, err := hashSource("", pom, []string{"md5", "sha1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []ArtifactoryBuildInfoModule{{
+		ID: "com.test.buildinfo:release:1.2.3",
+		Artifacts: []ArtifactoryBuildInfoArtifact{
+			{
+				Type: "zip",
+				Name: "release-1.2.3.zip",
+				MD5:  "60b725f10c9c85c70d97880dfe8191b3",
+				SHA1: "3f786850e387550fdab836ed7e6dc881de23001b",
+			},
+			{
+				Type: "pom",
+				Name: "release-1.2.3.pom",
+				MD5:  pomSums["md5"],
+				SHA1: pomSums["sha1"],
+			},
+		},
+	}}
+	if !reflect.DeepEqual(buildInfo.Modules, expected) {
+		t.Fatalf("expected modules %+v, got %+v", expected, buildInfo.Modules)
+	}
+}
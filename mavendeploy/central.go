@@ -0,0 +1,335 @@
+package mavendeploy
+
+import (
+	"archive/zip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Central configures publishing to Maven Central through the Central
+// Publisher Portal with the central backend. The repository layout of the
+// prepared artifacts, with poms, signatures and checksums, is uploaded as a
+// single bundle which the portal validates and then publishes.
+//
+// The portal user token is used as the repository username and password.
+type Central struct {
+	URL     string `json:"url"`     // portal url, default https://central.sonatype.com
+	Name    string `json:"name"`    // deployment name shown in the portal
+	Publish bool   `json:"publish"` // publish automatically once validated instead of waiting for a manual publish
+	Timeout string `json:"timeout"` // maximum wait for the validation or publishing, default 30m
+}
+
+// Central defaults.
+const (
+	defaultCentralURL     = "https://central.sonatype.com"
+	defaultCentralTimeout = 30 * time.Minute
+	centralPollInterval   = 5 * time.Second
+
+	// centralRepositoryURL is where published artifacts end up, the bundle
+	// files are reported at their future location.
+	centralRepositoryURL = "https://repo1.maven.org/maven2"
+)
+
+// centralChecksums are required by Maven Central for every file.
+var centralChecksums = []string{"md5", "sha1"}
+
+// Deployment states of the Central Publisher Portal, a deployment is
+// PENDING, VALIDATING or PUBLISHING in between.
+const (
+	centralValidated = "VALIDATED"
+	centralPublished = "PUBLISHED"
+	centralFailed    = "FAILED"
+)
+
+// validateCentral verifies the central backend configuration, Maven Central
+// requires signatures and md5 and sha1 checksums of every file.
+func (mvn *Maven) validateCentral() error {
+	if mvn.Args.Backend != BackendCentral {
+		return nil
+	}
+	if mvn.Staging.enabled() {
+		return fmt.Errorf("staging is not supported by the %s backend", BackendCentral)
+	}
+	if mvn.GPG.PrivateKey == "" {
+		return fmt.Errorf("gpg_private_key is %s by the %s backend", errRequiredValue, BackendCentral)
+	}
+	for _, v := range centralChecksums {
+		found := false
+		for _, c := range mvn.checksums() {
+			found = found || c == v
+		}
+		if !found {
+			return fmt.Errorf("checksum %s is %s by the %s backend", v, errRequiredValue, BackendCentral)
+		}
+	}
+	if t := mvn.Central.Timeout; t != "" {
+		d, err := time.ParseDuration(t)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("central timeout %s is %s", t, errInvalidValue)
+		}
+	}
+	return nil
+}
+
+func (c Central) timeout() time.Duration {
+	if d, err := time.ParseDuration(c.Timeout); err == nil {
+		return d
+	}
+	return defaultCentralTimeout
+}
+
+// CentralError is returned when the portal fails to validate or publish a
+// deployment, Errors holds the validation errors by component.
+type CentralError struct {
+	DeploymentID string
+	Errors       map[string][]string
+}
+
+func (e *CentralError) Error() string {
+	lines := []string{fmt.Sprintf("central deployment %s failed", e.DeploymentID)}
+	var keys []string
+	for k := range e.Errors {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range e.Errors[k] {
+			lines = append(lines, fmt.Sprintf("  %s: %s", k, v))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// publishCentral writes the prepared artifacts to a bundle, uploads it to
+// the portal and waits until it is validated, or published if publishing is
+// automatic.
+func (mvn *Maven) publishCentral() error {
+	f, err := ioutil.TempFile("", "drone-mvn-bundle")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	z := newZipTransport(f)
+	for _, key := range mvn.artifactKeys() {
+		err := mvn.deploy(z, mvn.artifacts[key])
+		if err != nil {
+			return err
+		}
+	}
+	err = z.Close()
+	if err != nil {
+		return err
+	}
+	size, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	c := mvn.centralClient()
+	var id string
+	err = mvn.retry("bundle", func() error {
+		// each attempt reads the bundle from the start on its own
+		var err error
+		id, err = c.upload(io.NewSectionReader(f, 0, size), mvn.centralName(), mvn.Central.Publish)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	mvn.infof("uploaded bundle as central deployment %s", id)
+	done := centralValidated
+	if mvn.Central.Publish {
+		done = centralPublished
+	}
+	timeout := mvn.Central.timeout()
+	for waited := time.Duration(0); ; waited += centralPollInterval {
+		s, err := c.status(id)
+		if err != nil {
+			return err
+		}
+		switch s.State {
+		case done:
+			mvn.infof("central deployment %s is %s", id, strings.ToLower(done))
+			return nil
+		case centralFailed:
+			return &CentralError{DeploymentID: id, Errors: s.Errors}
+		}
+		if waited >= timeout {
+			return fmt.Errorf("timed out after %s waiting for central deployment %s, it is %s", timeout, id, s.State)
+		}
+		mvn.pause(centralPollInterval)
+	}
+}
+
+func (mvn *Maven) centralName() string {
+	if mvn.Central.Name != "" {
+		return mvn.Central.Name
+	}
+	keys := mvn.artifactKeys()
+	if len(keys) == 1 {
+		return keys[0]
+	}
+	return fmt.Sprintf("%s and %d more", keys[0], len(keys)-1)
+}
+
+func (mvn *Maven) centralClient() *centralClient {
+	base := defaultCentralURL
+	if mvn.Central.URL != "" {
+		base = strings.TrimSuffix(mvn.Central.URL, "/")
+	}
+	return &centralClient{
+		base:   base + "/api/v1/publisher",
+		token:  base64.StdEncoding.EncodeToString([]byte(mvn.Repository.Username + ":" + mvn.Repository.Password)),
+		client: mvn.httpClient(),
+	}
+}
+
+// centralClient uses the Central Publisher Portal API.
+type centralClient struct {
+	base   string
+	token  string
+	client *http.Client
+}
+
+// centralStatus is the state of a portal deployment.
+type centralStatus struct {
+	DeploymentID string              `json:"deploymentId"`
+	State        string              `json:"deploymentState"`
+	Errors       map[string][]string `json:"errors"`
+}
+
+// upload uploads the bundle and returns the deployment id. The bundle is not
+// read anymore once upload returns.
+func (c *centralClient) upload(bundle io.Reader, name string, publish bool) (string, error) {
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		w, err := mw.CreateFormFile("bundle", "bundle.zip")
+		if err == nil {
+			_, err = io.Copy(w, bundle)
+		}
+		if err == nil {
+			err = mw.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+	// the request may fail before the whole bundle is written
+	defer func() {
+		pr.Close()
+		<-done
+	}()
+	publishingType := "USER_MANAGED"
+	if publish {
+		publishingType = "AUTOMATIC"
+	}
+	q := url.Values{"name": {name}, "publishingType": {publishingType}}
+	req, err := c.newRequest("POST", "/upload?"+q.Encode(), pr)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	res, err := c.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	data, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return "", err
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return "", newHTTPError(req, res)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// status returns the state of the deployment id.
+func (c *centralClient) status(id string) (*centralStatus, error) {
+	req, err := c.newRequest("POST", "/status?"+url.Values{"id": {id}}.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	res, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, newHTTPError(req, res)
+	}
+	var s centralStatus
+	err = json.NewDecoder(res.Body).Decode(&s)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (c *centralClient) newRequest(method, p string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequest(method, c.base+p, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	return req, nil
+}
+
+// zipTransport writes the files of a deployment to a bundle zip in the
+// repository layout. Nothing is ever found in the bundle and the artifact
+// level maven-metadata.xml files, which are maintained by Maven Central, are
+// left out.
+type zipTransport struct {
+	mu sync.Mutex
+	w  *zip.Writer
+}
+
+func newZipTransport(w io.Writer) *zipTransport {
+	return &zipTransport{w: zip.NewWriter(w)}
+}
+
+func (z *zipTransport) Get(p string) ([]byte, error) {
+	return nil, errNotFound
+}
+
+func (z *zipTransport) Exists(p string) (bool, error) {
+	return false, nil
+}
+
+func (z *zipTransport) Put(p string, r io.Reader, size int64) error {
+	if strings.HasPrefix(path.Base(p), metadataName) {
+		_, err := io.Copy(ioutil.Discard, r)
+		return err
+	}
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	w, err := z.w.Create(p)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, r)
+	return err
+}
+
+func (z *zipTransport) URL(p string) string {
+	return centralRepositoryURL + "/" + p
+}
+
+// Close finishes the bundle.
+func (z *zipTransport) Close() error {
+	return z.w.Close()
+}
@@ -0,0 +1,272 @@
+package mavendeploy
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCentral(t *testing.T) {
+	for _, v := range []struct {
+		publish bool
+		errors  map[string][]string // validation errors
+		states  []string            // expected polled states
+	}{
+		{false, nil, []string{"VALIDATING", "VALIDATED"}},
+		{true, nil, []string{"VALIDATING", "PUBLISHING", "PUBLISHED"}},
+		{false, map[string][]string{
+			"pkg:maven/com.test.central/release@1.0.0?type=zip": {"Javadocs must be provided but not found in entries"},
+		}, []string{"VALIDATING", "FAILED"}},
+	} {
+		p := newFakePortal()
+		p.errors = v.errors
+		server := httptest.NewServer(p)
+		var delays []time.Duration
+		mvn := &Maven{
+			Repository: Repository{
+				Username: "u",
+				Password: "p",
+			},
+			Artifact: Artifact{
+				GroupID:    "com.test.central",
+				ArtifactID: "release",
+				Version:    "1.0.0",
+			},
+			GPG: GPG{
+				PrivateKey: privateKey,
+				Passphrase: `test`,
+			},
+			Args: Args{
+				Source:  Patterns{"single/release.zip"},
+				Backend: BackendCentral,
+			},
+			Central: Central{
+				URL:     server.URL,
+				Publish: v.publish,
+			},
+			workspacePath: "test-data/",
+			quiet:         true,
+			sleep:         func(d time.Duration) { delays = append(delays, d) },
+		}
+		err := mvn.Publish()
+		server.Close()
+		if v.errors == nil && err != nil {
+			t.Fatalf("%+v: %v", v, err)
+		}
+		if v.errors != nil {
+			cerr, ok := err.(*CentralError)
+			if !ok || !reflect.DeepEqual(cerr.Errors, v.errors) {
+				t.Fatalf("%+v: expected validation errors, got %v", v, err)
+			}
+			if !strings.Contains(err.Error(), "Javadocs must be provided") {
+				t.Fatalf("unexpected error message %s", err)
+			}
+		}
+		if !reflect.DeepEqual(p.polled, v.states) {
+			t.Fatalf("%+v: expected states %v, got %v", v, v.states, p.polled)
+		}
+		if len(delays) != len(v.states)-1 {
+			t.Fatalf("%+v: unexpected delays %v", v, delays)
+		}
+		expectedType := "USER_MANAGED"
+		if v.publish {
+			expectedType = "AUTOMATIC"
+		}
+		if p.publishingType != expectedType || p.name != "com.test.central:release:1.0.0" {
+			t.Fatalf("%+v: unexpected upload %s %s", v, p.publishingType, p.name)
+		}
+		dir := "com/test/central/release/1.0.0/"
+		var expected []string
+		for _, f := range []string{"release-1.0.0.zip", "release-1.0.0.pom"} {
+			for _, ext := range []string{"", ".md5", ".sha1", ".asc", ".asc.md5", ".asc.sha1"} {
+				expected = append(expected, dir+f+ext)
+			}
+		}
+		sort.Strings(expected)
+		if !reflect.DeepEqual(p.files, expected) {
+			t.Fatalf("%+v: expected bundle files\n%s\ngot\n%s", v,
+				strings.Join(expected, "\n"), strings.Join(p.files, "\n"))
+		}
+	}
+}
+
+func TestCentralRetry(t *testing.T) {
+	p := newFakePortal()
+	p.unavailable = 2
+	server := httptest.NewServer(p)
+	defer server.Close()
+	mvn := &Maven{
+		Repository: Repository{
+			Username: "u",
+			Password: "p",
+		},
+		Artifact: Artifact{
+			GroupID:    "com.test.central",
+			ArtifactID: "release",
+			Version:    "1.0.0",
+		},
+		GPG: GPG{
+			PrivateKey: privateKey,
+			Passphrase: `test`,
+		},
+		Args: Args{
+			Source:  Patterns{"single/release.zip"},
+			Backend: BackendCentral,
+			Retry:   Retry{Attempts: 3},
+		},
+		Central: Central{
+			URL: server.URL,
+		},
+		workspacePath: "test-data/",
+		quiet:         true,
+		sleep:         func(time.Duration) {},
+	}
+	err := mvn.Publish()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the bundle of the last attempt is complete
+	if p.uploads != 3 || len(p.files) != 12 {
+		t.Fatalf("expected a complete bundle on the third upload, got %d uploads of %v", p.uploads, p.files)
+	}
+}
+
+func TestCentralInvalid(t *testing.T) {
+	for _, v := range []struct {
+		version   string
+		key       string
+		checksums []string
+	}{
+		{"1.0.0-SNAPSHOT", privateKey, nil},
+		{"1.0.0", "", nil},
+		{"1.0.0", privateKey, []string{"sha256"}},
+	} {
+		mvn := &Maven{
+			Repository: Repository{
+				Username: "u",
+				Password: "p",
+			},
+			Artifact: Artifact{
+				GroupID:    "com.test.central",
+				ArtifactID: "release",
+				Version:    v.version,
+			},
+			GPG: GPG{
+				PrivateKey: v.key,
+				Passphrase: `test`,
+			},
+			Args: Args{
+				Source:    Patterns{"single/release.zip"},
+				Backend:   BackendCentral,
+				Checksums: v.checksums,
+			},
+			Central: Central{
+				URL: "http://127.0.0.1:1",
+			},
+			workspacePath: "test-data/",
+			quiet:         true,
+		}
+		if err := mvn.Publish(); err == nil {
+			t.Fatalf("%+v: expected error", v)
+		}
+	}
+}
+
+// fakePortal is a minimal Central Publisher Portal which accepts bundles
+// uploaded with the token of the user u with password p. Deployments are
+// validating, and publishing, for one status request each.
+type fakePortal struct {
+	mu             sync.Mutex
+	unavailable    int                 // uploads failing before the bundle is read
+	uploads        int                 // upload requests
+	errors         map[string][]string // validation errors
+	files          []string            // sorted bundle files
+	name           string
+	publishingType string
+	polled         []string // polled deployment states
+}
+
+func newFakePortal() *fakePortal {
+	return &fakePortal{}
+}
+
+const fakeDeploymentID = "28570f16-da32-4c14-bd2e-c1acc0782365"
+
+func (f *fakePortal) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	token := base64.StdEncoding.EncodeToString([]byte("u:p"))
+	if r.Header.Get("Authorization") != "Bearer "+token {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	switch {
+	case r.Method == "POST" && r.URL.Path == "/api/v1/publisher/upload":
+		f.uploads++
+		if f.uploads <= f.unavailable {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		file, _, err := r.FormFile("bundle")
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		data, err := ioutil.ReadAll(file)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		z, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.files = nil
+		for _, v := range z.File {
+			f.files = append(f.files, v.Name)
+		}
+		sort.Strings(f.files)
+		f.name = r.URL.Query().Get("name")
+		f.publishingType = r.URL.Query().Get("publishingType")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(fakeDeploymentID))
+	case r.Method == "POST" && r.URL.Path == "/api/v1/publisher/status":
+		if r.URL.Query().Get("id") != fakeDeploymentID {
+			http.NotFound(w, r)
+			return
+		}
+		states := []string{"VALIDATING", "VALIDATED"}
+		switch {
+		case f.errors != nil:
+			states = []string{"VALIDATING", "FAILED"}
+		case f.publishingType == "AUTOMATIC":
+			states = []string{"VALIDATING", "PUBLISHING", "PUBLISHED"}
+		}
+		state := states[len(states)-1]
+		if len(f.polled) < len(states) {
+			state = states[len(f.polled)]
+		}
+		f.polled = append(f.polled, state)
+		res := map[string]interface{}{
+			"deploymentId":    fakeDeploymentID,
+			"deploymentState": state,
+		}
+		if state == "FAILED" {
+			res["errors"] = f.errors
+		}
+		json.NewEncoder(w).Encode(res)
+	default:
+		http.NotFound(w, r)
+	}
+}
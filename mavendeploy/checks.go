@@ -0,0 +1,133 @@
+package mavendeploy
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+)
+
+// CentralChecksError is returned by Prepare when central checks are enabled
+// and artifact groups don't meet the Maven Central requirements, the
+// violations are keyed by groupID:artifactID:version.
+type CentralChecksError map[string][]string
+
+func (e CentralChecksError) Error() string {
+	var keys []string
+	for k := range e {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	lines := []string{fmt.Sprintf("%d artifact groups don't meet the maven central requirements:", len(keys))}
+	for _, k := range keys {
+		lines = append(lines, "  "+k+":")
+		for _, v := range e[k] {
+			lines = append(lines, "    - "+v)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// checkCentral validates the prepared artifact groups against the Maven
+// Central requirements so that they fail before anything is uploaded instead
+// of in the staging rules or the portal validation.
+func (mvn *Maven) checkCentral() error {
+	errs := make(CentralChecksError)
+	for _, key := range mvn.artifactKeys() {
+		violations, err := mvn.centralViolations(key)
+		if err != nil {
+			return err
+		}
+		if len(violations) > 0 {
+			errs[key] = violations
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// centralViolations returns the Maven Central requirements not met by the
+// artifact group key.
+func (mvn *Maven) centralViolations(key string) ([]string, error) {
+	artifacts := mvn.artifacts[key]
+	a := artifacts[0]
+	var violations []string
+	if isSnapshot(a.Version) {
+		violations = append(violations, fmt.Sprintf("version %s is a snapshot", a.Version))
+	}
+	if ns := mvn.Args.CentralNamespaces; len(ns) > 0 && !inNamespace(a.GroupID, ns) {
+		violations = append(violations, fmt.Sprintf("group %s is not in a verified namespace (%s)",
+			a.GroupID, strings.Join(ns, ", ")))
+	}
+	p, err := mvn.project(key)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range []struct {
+		name    string
+		missing bool
+	}{
+		{"name", p.Name == ""},
+		{"description", p.Description == "" || p.Description == defaultDescription},
+		{"url", p.URL == ""},
+		{"license", p.Licenses == nil || len(p.Licenses.License) == 0},
+		{"developer", p.Developers == nil || len(p.Developers.Developer) == 0},
+		{"scm", p.SCM == nil || p.SCM.URL == ""},
+	} {
+		if v.missing {
+			violations = append(violations, "pom has no "+v.name)
+		}
+	}
+	if mvn.GPG.PrivateKey == "" {
+		violations = append(violations, "files are not signed, gpg_private_key is not set")
+	}
+	if p.Packaging == "" || p.Packaging == "jar" {
+		classifiers := make(map[string]bool)
+		for _, v := range artifacts {
+			if v.extension() == "jar" {
+				classifiers[v.Classifier] = true
+			}
+		}
+		for _, c := range []string{"sources", "javadoc"} {
+			if !classifiers[c] {
+				violations = append(violations, fmt.Sprintf("jar packaging requires a %s jar", c))
+			}
+		}
+	}
+	return violations, nil
+}
+
+// project returns the pom of the artifact group key, the pom file is read if
+// one is used.
+func (mvn *Maven) project(key string) (*Project, error) {
+	filename, data, err := mvn.pomSource(key)
+	if err != nil {
+		return nil, err
+	}
+	if filename == "" {
+		return mvn.projects[key], nil
+	}
+	data, err = ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var p Project
+	err = xml.Unmarshal(data, &p)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse %s: %v", filename, err)
+	}
+	return &p, nil
+}
+
+// inNamespace returns true if group is one of the namespaces or below one.
+func inNamespace(group string, namespaces []string) bool {
+	for _, ns := range namespaces {
+		if group == ns || strings.HasPrefix(group, ns+".") {
+			return true
+		}
+	}
+	return false
+}
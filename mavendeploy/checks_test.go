@@ -0,0 +1,89 @@
+package mavendeploy
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCentralChecks(t *testing.T) {
+	complete := POM{
+		Name:        "lib",
+		Description: "a library",
+		URL:         "https://example.com/lib",
+		Licenses:    []License{{Name: "MIT", URL: "https://opensource.org/licenses/MIT"}},
+		Developers:  []Developer{{Name: "Test"}},
+		SCM:         &SCM{URL: "https://example.com/lib.git"},
+	}
+	for _, v := range []struct {
+		source     string
+		version    string
+		pom        POM
+		pomFile    string
+		key        string
+		namespaces []string
+		expected   []string
+	}{
+		{"jar/lib-2.0.0*.jar", "2.0.0", complete, "", privateKey, []string{"com.example", "com.test"}, nil},
+		{"jar/lib-2.0.0.jar", "2.0.0-SNAPSHOT", POM{}, "", "", []string{"com.test.checks.lib"}, []string{
+			"version 2.0.0-SNAPSHOT is a snapshot",
+			"group com.test.checks is not in a verified namespace (com.test.checks.lib)",
+			"pom has no name",
+			"pom has no description",
+			"pom has no url",
+			"pom has no license",
+			"pom has no developer",
+			"pom has no scm",
+			"files are not signed, gpg_private_key is not set",
+			"jar packaging requires a sources jar",
+			"jar packaging requires a javadoc jar",
+		}},
+		{"single/release.zip", "1.2.3", complete, "poms/release.pom", privateKey, nil, []string{
+			"pom has no description",
+			"pom has no url",
+			"pom has no license",
+			"pom has no developer",
+			"pom has no scm",
+		}},
+	} {
+		mvn := &Maven{
+			Artifact: Artifact{
+				GroupID:    "com.test.checks",
+				ArtifactID: "lib",
+				Version:    v.version,
+			},
+			GPG: GPG{
+				PrivateKey: v.key,
+			},
+			Args: Args{
+				Source:            Patterns{v.source},
+				Regexp:            `(?P<artifact>lib)-[0-9.]+(-(?P<classifier>sources|javadoc))?\.(?P<extension>jar)$`,
+				PomFile:           v.pomFile,
+				CentralChecks:     true,
+				CentralNamespaces: v.namespaces,
+			},
+			POM:           v.pom,
+			workspacePath: "test-data/",
+			quiet:         true,
+		}
+		if v.pomFile != "" {
+			mvn.Artifact = Artifact{GroupID: "com.test.pomfile", ArtifactID: "release", Version: v.version}
+			mvn.Args.Regexp = ""
+		}
+		err := mvn.Prepare()
+		if v.expected == nil {
+			if err != nil {
+				t.Fatalf("%s: %v", v.source, err)
+			}
+			continue
+		}
+		errs, ok := err.(CentralChecksError)
+		if !ok || len(errs) != 1 {
+			t.Fatalf("%s: expected central checks error, got %v", v.source, err)
+		}
+		for _, violations := range errs {
+			if !reflect.DeepEqual(violations, v.expected) {
+				t.Fatalf("%s: expected violations\n%v\ngot\n%v", v.source, v.expected, violations)
+			}
+		}
+	}
+}
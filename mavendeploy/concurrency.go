@@ -0,0 +1,176 @@
+package mavendeploy
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// deployment records the results of a publish. It is shared by the copies of
+// Maven used to deploy artifact groups concurrently.
+type deployment struct {
+	mu             sync.Mutex
+	uploads        []upload               // files uploaded by the native backend
+	uniqueVersions map[string]string      // deployed snapshot versions by artifact key
+	locks          map[string]*sync.Mutex // repository path locks
+
+	stagingRepository string // id of the opened staging repository
+}
+
+func newDeployment() *deployment {
+	return &deployment{
+		uniqueVersions: make(map[string]string),
+		locks:          make(map[string]*sync.Mutex),
+	}
+}
+
+// record adds uploaded files.
+func (d *deployment) record(uploads ...upload) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.uploads = append(d.uploads, uploads...)
+}
+
+// setUniqueVersion records the unique snapshot version of an artifact group.
+func (d *deployment) setUniqueVersion(key, version string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.uniqueVersions[key] = version
+}
+
+// lock locks the repository path p, which is used to serialize updates of
+// the metadata shared by different versions of an artifact.
+func (d *deployment) lock(p string) (unlock func()) {
+	d.mu.Lock()
+	l, ok := d.locks[p]
+	if !ok {
+		l = &sync.Mutex{}
+		d.locks[p] = l
+	}
+	d.mu.Unlock()
+	l.Lock()
+	return l.Unlock
+}
+
+// GroupErrors is returned when artifact groups failed to deploy, the errors
+// are keyed by groupID:artifactID:version.
+type GroupErrors map[string]error
+
+func (e GroupErrors) Error() string {
+	var keys []string
+	for k := range e {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	if len(keys) == 1 {
+		return fmt.Sprintf("%s: %v", keys[0], e[keys[0]])
+	}
+	lines := []string{fmt.Sprintf("%d artifact groups failed to deploy:", len(keys))}
+	for _, k := range keys {
+		lines = append(lines, fmt.Sprintf("  %s: %v", k, e[k]))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Unwrap returns the errors of the groups sorted by key, so that errors.As
+// finds the error of a failed group.
+func (e GroupErrors) Unwrap() []error {
+	var keys []string
+	for k := range e {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var errs []error
+	for _, k := range keys {
+		errs = append(errs, e[k])
+	}
+	return errs
+}
+
+// forEachGroup calls f for each prepared artifact group, sequentially unless
+// a concurrency above one is configured. All groups are attempted and the
+// errors are returned as GroupErrors.
+//
+// Concurrent groups are handled by copies of mvn which buffer their output,
+// the output is written prefixed with the group key once the group is done.
+func (mvn *Maven) forEachGroup(f func(g *Maven, key string) error) error {
+	keys := mvn.artifactKeys()
+	workers := mvn.Args.Concurrency
+	if workers > len(keys) {
+		workers = len(keys)
+	}
+	errs := make(GroupErrors)
+	if workers <= 1 {
+		for _, key := range keys {
+			err := f(mvn, key)
+			if err != nil {
+				mvn.infof("%s failed: %v", key, err)
+				errs[key] = err
+			}
+		}
+		if len(errs) > 0 {
+			return errs
+		}
+		return nil
+	}
+	var (
+		mu   sync.Mutex // guards errs and the output
+		wg   sync.WaitGroup
+		jobs = make(chan string)
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range jobs {
+				var buf bytes.Buffer
+				g := *mvn
+				g.log = &buf
+				err := f(&g, key)
+				if err != nil {
+					g.infof("failed: %v", err)
+				}
+				mu.Lock()
+				writePrefixed(mvn.logWriter(), "["+key+"] ", buf.Bytes())
+				if err != nil {
+					errs[key] = err
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, key := range keys {
+		jobs <- key
+	}
+	close(jobs)
+	wg.Wait()
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// writePrefixed writes each line of data to w prefixed with prefix.
+func writePrefixed(w io.Writer, prefix string, data []byte) {
+	for _, line := range strings.SplitAfter(string(data), "\n") {
+		if line == "" {
+			continue
+		}
+		if !strings.HasSuffix(line, "\n") {
+			line += "\n"
+		}
+		io.WriteString(w, prefix+line)
+	}
+}
+
+// logWriter returns the writer used for log output.
+func (mvn *Maven) logWriter() io.Writer {
+	if mvn.log != nil {
+		return mvn.log
+	}
+	return os.Stdout
+}
@@ -0,0 +1,117 @@
+package mavendeploy
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNativeConcurrency(t *testing.T) {
+	l := LocalTest{
+		t,
+		&Maven{
+			Repository: Repository{
+				Username: "u",
+				Password: "p",
+			},
+			Artifact: Artifact{
+				GroupID: "com.test.publish1",
+			},
+			Args: Args{
+				Source:      Patterns{"multiple-matched/app*"},
+				Regexp:      "(?P<artifact>app-[^/-]*)-(?P<classifier>[^-]*-[^-]*)-(?P<version>.*).(?P<extension>tar.gz|zip|readme)$",
+				Backend:     BackendNative,
+				Concurrency: 3,
+			}}}
+
+	l.Run(func(m *Maven) {
+		err := m.Publish()
+		if err != nil {
+			t.Fatal(err)
+		}
+		l.AssertFiles(publish1Files...)
+	})
+}
+
+func TestNativeConcurrencyErrors(t *testing.T) {
+	// every group is attempted and failures are reported the same way
+	// regardless of the concurrency
+	for _, concurrency := range []int{0, 1, 2} {
+		repo := newFakeRepo()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == "PUT" && strings.Contains(r.URL.Path, "/app-gui/") {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			repo.ServeHTTP(w, r)
+		}))
+
+		var log bytes.Buffer
+		mvn := &Maven{
+			Repository: Repository{
+				Username: "u",
+				Password: "p",
+				URL:      server.URL,
+			},
+			Artifact: Artifact{
+				GroupID: "com.test.concurrency",
+			},
+			Args: Args{
+				Source:      Patterns{"multiple-matched/app*"},
+				Regexp:      "(?P<artifact>app-[^/-]*)-(?P<classifier>[^-]*-[^-]*)-(?P<version>.*).(?P<extension>tar.gz|zip|readme)$",
+				Backend:     BackendNative,
+				Concurrency: concurrency,
+			},
+			workspacePath: "test-data/",
+			log:           &log,
+		}
+		err := mvn.Publish()
+		server.Close()
+		errs, ok := err.(GroupErrors)
+		if !ok || len(errs) != 1 {
+			t.Fatalf("concurrency %d: expected one group error, got %v", concurrency, err)
+		}
+		if _, ok := errs["com.test.concurrency:app-gui:0.1.4"].(*httpError); !ok {
+			t.Fatalf("concurrency %d: expected app-gui http error, got %v", concurrency, err)
+		}
+		var clients, servers int
+		for _, v := range repo.Files() {
+			switch {
+			case strings.Contains(v, "/app-client/"):
+				clients++
+			case strings.Contains(v, "/app-server/"):
+				servers++
+			}
+		}
+		if clients != 21 || servers != 12 {
+			t.Fatalf("concurrency %d: expected app-client and app-server to be deployed, got:\n%s",
+				concurrency, strings.Join(repo.Files(), "\n"))
+		}
+		if concurrency <= 1 {
+			if !strings.Contains(log.String(), "$ com.test.concurrency:app-gui:0.1.4 failed: PUT") {
+				t.Fatalf("expected app-gui failure in output:\n%s", log.String())
+			}
+			continue
+		}
+
+		// the output of each group is written in one piece prefixed by the key
+		var keys []string
+		for _, line := range strings.Split(strings.TrimSpace(log.String()), "\n") {
+			if !strings.HasPrefix(line, "[com.test.concurrency:") {
+				continue
+			}
+			key := line[:strings.Index(line, "]")+1]
+			if len(keys) == 0 || keys[len(keys)-1] != key {
+				keys = append(keys, key)
+			}
+		}
+		if len(keys) != 3 {
+			t.Fatalf("expected output of 3 groups without interleaving, got %v:\n%s", keys, log.String())
+		}
+		if !strings.Contains(log.String(), "[com.test.concurrency:app-gui:0.1.4] $ failed: PUT") {
+			t.Fatalf("expected failure in app-gui output:\n%s", log.String())
+		}
+	}
+}
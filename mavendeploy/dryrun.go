@@ -0,0 +1,66 @@
+package mavendeploy
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+// dryRunTransport resolves repository locations without reading from or
+// writing to the repository. Nothing is ever found in the repository so
+// metadata is shown as if the artifact was deployed for the first time.
+type dryRunTransport struct {
+	transport
+}
+
+func (d dryRunTransport) Get(p string) ([]byte, error) {
+	return nil, errNotFound
+}
+
+func (d dryRunTransport) Exists(p string) (bool, error) {
+	return false, nil
+}
+
+func (d dryRunTransport) Put(p string, r io.Reader, size int64) error {
+	_, err := io.Copy(ioutil.Discard, r)
+	return err
+}
+
+// dryRun runs the native deployment of the prepared artifacts against a
+// dryRunTransport and prints the resulting table of source file and remote
+// location for every file that would be uploaded.
+func (mvn *Maven) dryRun() error {
+	mvn.deployment = newDeployment()
+	for _, key := range mvn.artifactKeys() {
+		t, err := mvn.transport(mvn.artifacts[key][0])
+		if err != nil {
+			return err
+		}
+		err = mvn.deploy(dryRunTransport{t}, mvn.artifacts[key])
+		if err != nil {
+			return err
+		}
+	}
+	w := tabwriter.NewWriter(mvn.stdout(), 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "dry run, nothing is deployed")
+	fmt.Fprintln(w, "FILE\tREMOTE")
+	for _, v := range mvn.deployment.uploads {
+		source := v.Source
+		if !strings.HasPrefix(source, "(") {
+			source = mvn.relPath(source)
+		}
+		fmt.Fprintf(w, "%s\t%s\n", source, v.URL)
+	}
+	return w.Flush()
+}
+
+// stdout returns the writer used for output which is not logging.
+func (mvn *Maven) stdout() io.Writer {
+	if mvn.output != nil {
+		return mvn.output
+	}
+	return os.Stdout
+}
@@ -0,0 +1,124 @@
+package mavendeploy
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNativeDryRun(t *testing.T) {
+	repo := newFakeRepo()
+	server := httptest.NewServer(repo)
+	defer server.Close()
+
+	var output bytes.Buffer
+	mvn := &Maven{
+		Repository: Repository{
+			URL: server.URL,
+		},
+		Artifact: Artifact{
+			GroupID:    "com.test.dryrun",
+			ArtifactID: "release",
+			Extension:  "zip",
+			Version:    "1.2.3",
+		},
+		GPG: GPG{
+			PrivateKey: privateKey,
+			Passphrase: `test`,
+		},
+		Args: Args{
+			Source:  Patterns{"single/release.zip"},
+			Backend: BackendNative,
+			DryRun:  true,
+		},
+		workspacePath: "test-data/",
+		output:        &output,
+		quiet:         true,
+	}
+	err := mvn.Publish()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if files := repo.Files(); len(files) > 0 {
+		t.Fatalf("expected no uploads, got:\n%s", strings.Join(files, "\n"))
+	}
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	expected := [][]string{
+		{"dry", "run,", "nothing", "is", "deployed"},
+		{"FILE", "REMOTE"},
+		{"single/release.zip", server.URL + "/com/test/dryrun/release/1.2.3/release-1.2.3.zip"},
+		{"(md5)", server.URL + "/com/test/dryrun/release/1.2.3/release-1.2.3.zip.md5"},
+		{"(sha1)", server.URL + "/com/test/dryrun/release/1.2.3/release-1.2.3.zip.sha1"},
+		{"(signature)", server.URL + "/com/test/dryrun/release/1.2.3/release-1.2.3.zip.asc"},
+		{"(md5)", server.URL + "/com/test/dryrun/release/1.2.3/release-1.2.3.zip.asc.md5"},
+		{"(sha1)", server.URL + "/com/test/dryrun/release/1.2.3/release-1.2.3.zip.asc.sha1"},
+		{"(generated", "pom)", server.URL + "/com/test/dryrun/release/1.2.3/release-1.2.3.pom"},
+		{"(md5)", server.URL + "/com/test/dryrun/release/1.2.3/release-1.2.3.pom.md5"},
+		{"(sha1)", server.URL + "/com/test/dryrun/release/1.2.3/release-1.2.3.pom.sha1"},
+		{"(signature)", server.URL + "/com/test/dryrun/release/1.2.3/release-1.2.3.pom.asc"},
+		{"(md5)", server.URL + "/com/test/dryrun/release/1.2.3/release-1.2.3.pom.asc.md5"},
+		{"(sha1)", server.URL + "/com/test/dryrun/release/1.2.3/release-1.2.3.pom.asc.sha1"},
+		{"(generated", "metadata)", server.URL + "/com/test/dryrun/release/maven-metadata.xml"},
+		{"(md5)", server.URL + "/com/test/dryrun/release/maven-metadata.xml.md5"},
+		{"(sha1)", server.URL + "/com/test/dryrun/release/maven-metadata.xml.sha1"},
+	}
+	if len(lines) != len(expected) {
+		t.Fatalf("unexpected output:\n%s", output.String())
+	}
+	for i, v := range expected {
+		if strings.Join(strings.Fields(lines[i]), " ") != strings.Join(v, " ") {
+			t.Fatalf("unexpected line %d: %s\n\n%s", i, lines[i], output.String())
+		}
+	}
+}
+
+func TestMvnDryRun(t *testing.T) {
+	var output, log bytes.Buffer
+	mvn := &Maven{
+		Repository: Repository{
+			URL: "http://localhost/repo",
+		},
+		Artifact: Artifact{
+			GroupID:    "com.test.dryrun",
+			ArtifactID: "release",
+			Extension:  "zip",
+			Version:    "1.2.3",
+		},
+		GPG: GPG{
+			PrivateKey: privateKey,
+			Passphrase: `wrong`,
+		},
+		Args: Args{
+			Source:  Patterns{"single/release.zip"},
+			Backend: BackendMvn,
+			DryRun:  true,
+		},
+		workspacePath: "test-data/",
+		output:        &output,
+		log:           &log,
+	}
+	if err := mvn.Publish(); err == nil {
+		t.Fatal("expected the wrong passphrase to fail the dry run")
+	}
+	mvn.GPG.Passphrase = `test`
+	err := mvn.Publish()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{"release-1.2.3.zip.asc\n", "release-1.2.3.pom.asc\n"} {
+		if !strings.Contains(output.String(), v) {
+			t.Fatalf("expected planned signature %s in output:\n%s", strings.TrimSpace(v), output.String())
+		}
+	}
+	for _, v := range []string{
+		"--settings settings.xml ",
+		"-DpomFile=com.test.dryrun-release-1.2.3.pom ",
+		"-Dfiles=test-data/single/release.zip.asc,com.test.dryrun-release-1.2.3.pom.asc ",
+		"-Dtypes=zip.asc,pom.asc",
+	} {
+		if !strings.Contains(log.String(), v) {
+			t.Fatalf("expected %s in mvn command:\n%s", v, log.String())
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package mavendeploy
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Policies for release versions which already exist in the repository.
+const (
+	OnExistingOverwrite       = "overwrite"         // deploy anyway (default)
+	OnExistingFail            = "fail"              // abort the deployment
+	OnExistingSkip            = "skip"              // skip the artifact group
+	OnExistingSkipIfIdentical = "skip-if-identical" // skip if the remote files have the same checksums
+)
+
+// existsError is returned when a release version is already deployed and the
+// on_existing policy doesn't allow deploying it again.
+type existsError struct {
+	Key  string
+	URLs []string // existing or differing remote files
+	Msg  string
+}
+
+func (e *existsError) Error() string {
+	return fmt.Sprintf("%s %s: %s", e.Key, e.Msg, strings.Join(e.URLs, ", "))
+}
+
+// target is a file deployed for an artifact group, read from either data or
+// filename.
+type target struct {
+	path     string
+	filename string
+	data     []byte
+}
+
+// targets returns the artifact files and the pom of the artifact group key.
+func (mvn *Maven) targets(key string) ([]target, error) {
+	artifacts := mvn.artifacts[key]
+	dir := artifacts[0].versionDir()
+	var targets []target
+	for _, a := range artifacts {
+		targets = append(targets, target{path: path.Join(dir, a.fileName()), filename: a.file})
+	}
+	filename, data, err := mvn.pomSource(key)
+	if err != nil {
+		return nil, err
+	}
+	targets = append(targets, target{path.Join(dir, artifacts[0].pomName()), filename, data})
+	return targets, nil
+}
+
+// checkExisting applies the on_existing policy to the artifact group key
+// before it is deployed to t. A release version exists if any of its files,
+// or the version in the artifact level maven-metadata.xml, is found.
+// Snapshots are always deployed as they get a new unique version. Staged
+// releases are looked up in the release repository they are promoted to.
+//
+// It returns true if the artifact group should not be deployed. Lookups
+// failing temporarily are retried like the deployment.
+func (mvn *Maven) checkExisting(t transport, key string) (skip bool, err error) {
+	err = mvn.retry(key, func() error {
+		var err error
+		skip, err = mvn.lookupExisting(t, key)
+		return err
+	})
+	return skip, err
+}
+
+func (mvn *Maven) lookupExisting(t transport, key string) (skip bool, err error) {
+	policy := mvn.Args.OnExisting
+	a := mvn.artifacts[key][0]
+	if policy == "" || policy == OnExistingOverwrite || isSnapshot(a.Version) {
+		return false, nil
+	}
+	if mvn.staged(a.Version) {
+		t, err = mvn.releaseTransport(a.Version)
+		if err != nil {
+			return false, err
+		}
+	}
+	targets, err := mvn.targets(key)
+	if err != nil {
+		return false, err
+	}
+	var existing []target
+	for _, v := range targets {
+		ok, err := t.Exists(v.path)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			existing = append(existing, v)
+		}
+	}
+	inMetadata := false
+	if len(existing) == 0 {
+		m, err := fetchMetadata(t, a)
+		if err != nil {
+			return false, err
+		}
+		for _, v := range m.Versioning.Versions {
+			if v == a.Version {
+				inMetadata = true
+			}
+		}
+		if !inMetadata {
+			return false, nil
+		}
+	}
+	var urls []string
+	for _, v := range existing {
+		urls = append(urls, t.URL(v.path))
+	}
+	if inMetadata {
+		urls = append(urls, t.URL(path.Join(a.artifactDir(), metadataName)))
+	}
+	switch policy {
+	case OnExistingFail:
+		return false, &existsError{Key: key, URLs: urls, Msg: "is already deployed"}
+	case OnExistingSkip:
+		mvn.infof("%s is already deployed, skipping", key)
+		return true, nil
+	}
+	var differing []string
+	for _, v := range existing {
+		same, err := mvn.identical(t, v)
+		if err != nil {
+			return false, err
+		}
+		if !same {
+			differing = append(differing, t.URL(v.path))
+		}
+	}
+	if len(differing) > 0 {
+		return false, &existsError{Key: key, URLs: differing, Msg: "is already deployed with different content"}
+	}
+	if len(existing) < len(targets) {
+		mvn.infof("%s is partially deployed with identical content, deploying", key)
+		return false, nil
+	}
+	mvn.infof("%s is already deployed with identical content, skipping", key)
+	return true, nil
+}
+
+// identicalChecksums are the remote checksum files compared by identical in
+// order of preference.
+var identicalChecksums = []string{"sha256", "sha1"}
+
+// identical compares the remote checksum of v with the checksum of the local
+// content. A file without a remote sha256 or sha1 checksum is not identical.
+func (mvn *Maven) identical(t transport, v target) (bool, error) {
+	for _, ext := range identicalChecksums {
+		data, err := t.Get(v.path + "." + ext)
+		if err == errNotFound {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		// checksum files may contain the file name after the checksum
+		fields := strings.Fields(string(data))
+		if len(fields) == 0 {
+			return false, nil
+		}
+		_, sums, err := hashSource(v.filename, v.data, []string{ext})
+		if err != nil {
+			return false, err
+		}
+		return strings.ToLower(fields[0]) == sums[ext], nil
+	}
+	return false, nil
+}
@@ -0,0 +1,250 @@
+package mavendeploy
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOnExisting(t *testing.T) {
+	for _, v := range []struct {
+		policy   string
+		modified bool // the second deployment has different content
+		err      bool
+		puts     int // uploads of the second deployment
+	}{
+		{OnExistingOverwrite, false, false, 9},
+		{"", true, false, 9},
+		{OnExistingFail, false, true, 0},
+		{OnExistingSkip, true, false, 0},
+		{OnExistingSkipIfIdentical, false, false, 0},
+		{OnExistingSkipIfIdentical, true, true, 0},
+	} {
+		dir, err := ioutil.TempDir("", "drone-mvn-existing")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		err = ioutil.WriteFile(filepath.Join(dir, "release.zip"), []byte("1\n"), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		repo := newFakeRepo()
+		puts := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == "PUT" {
+				puts++
+			}
+			repo.ServeHTTP(w, r)
+		}))
+		publish := func() error {
+			mvn := &Maven{
+				Repository: Repository{
+					Username: "u",
+					Password: "p",
+					URL:      server.URL,
+				},
+				Artifact: Artifact{
+					GroupID:    "com.test.existing",
+					ArtifactID: "release",
+					Version:    "1.0.0",
+				},
+				Args: Args{
+					Source:     Patterns{"release.zip"},
+					Backend:    BackendNative,
+					Checksums:  []string{"sha1", "sha256"},
+					OnExisting: v.policy,
+				},
+				workspacePath: dir,
+				quiet:         true,
+			}
+			return mvn.Publish()
+		}
+		err = publish()
+		if err != nil {
+			t.Fatalf("%+v: %v", v, err)
+		}
+		if v.modified {
+			err = ioutil.WriteFile(filepath.Join(dir, "release.zip"), []byte("2\n"), 0644)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		before := len(repo.Files())
+		puts = 0
+		err = publish()
+		server.Close()
+		var eerr *existsError
+		if ok := errors.As(err, &eerr); ok != v.err {
+			t.Fatalf("%+v: unexpected result %v", v, err)
+		}
+		if puts != v.puts {
+			t.Fatalf("%+v: expected %d uploads, got %d", v, v.puts, puts)
+		}
+		if len(repo.Files()) != before {
+			t.Fatalf("%+v: unexpected files %v", v, repo.Files())
+		}
+	}
+}
+
+func TestOnExistingRetry(t *testing.T) {
+	repo := newFakeRepo()
+	failed := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "HEAD" && !failed {
+			failed = true
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		repo.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+	var delays []time.Duration
+	mvn := &Maven{
+		Repository: Repository{
+			Username: "u",
+			Password: "p",
+			URL:      server.URL,
+		},
+		Artifact: Artifact{
+			GroupID:    "com.test.existing",
+			ArtifactID: "release",
+			Version:    "1.0.0",
+		},
+		Args: Args{
+			Source:     Patterns{"single/release.zip"},
+			Backend:    BackendNative,
+			OnExisting: OnExistingFail,
+			Retry:      Retry{Attempts: 2},
+		},
+		workspacePath: "test-data/",
+		quiet:         true,
+		sleep:         func(d time.Duration) { delays = append(delays, d) },
+	}
+	err := mvn.Publish()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(delays) != 1 || len(repo.Files()) != 9 {
+		t.Fatalf("expected the failed lookup to be retried once, got delays %v and files %v", delays, repo.Files())
+	}
+}
+
+func TestOnExistingSnapshot(t *testing.T) {
+	repo := newFakeRepo()
+	server := httptest.NewServer(repo)
+	defer server.Close()
+	for i := 0; i < 2; i++ {
+		mvn := &Maven{
+			Repository: Repository{
+				Username: "u",
+				Password: "p",
+				URL:      server.URL,
+			},
+			Artifact: Artifact{
+				GroupID:    "com.test.existing",
+				ArtifactID: "snapshot",
+				Version:    "1.0.0-SNAPSHOT",
+			},
+			Args: Args{
+				Source:     Patterns{"single/release.zip"},
+				Backend:    BackendNative,
+				OnExisting: OnExistingFail,
+			},
+			workspacePath: "test-data/",
+			quiet:         true,
+		}
+		err := mvn.Publish()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestOnExistingInvalid(t *testing.T) {
+	mvn := &Maven{
+		Repository: Repository{
+			Username: "u",
+			Password: "p",
+			URL:      "file:///nonexistent",
+		},
+		Args: Args{
+			Source:     Patterns{"single/release.zip"},
+			OnExisting: "ignore",
+		},
+		workspacePath: "test-data/",
+		quiet:         true,
+	}
+	if err := mvn.Publish(); err != errInvalidValue {
+		t.Fatalf("expected invalid value error, got %v", err)
+	}
+}
+
+func TestOnExistingStaging(t *testing.T) {
+	for _, released := range []bool{false, true} {
+		s := newFakeStaging()
+		if released {
+			s.repo.files["/content/repositories/releases/com/test/staging/release/1.0.0/release-1.0.0.zip"] = []byte("1\n")
+		}
+		server := httptest.NewServer(s)
+		mvn := &Maven{
+			Repository: Repository{
+				Username:   "u",
+				Password:   "p",
+				ReleaseURL: server.URL + "/content/repositories/releases",
+			},
+			Artifact: Artifact{
+				GroupID:    "com.test.staging",
+				ArtifactID: "release",
+				Version:    "1.0.0",
+			},
+			Args: Args{
+				Source:     Patterns{"single/release.zip"},
+				Backend:    BackendNative,
+				OnExisting: OnExistingFail,
+			},
+			Staging: Staging{
+				URL:       server.URL,
+				ProfileID: "12a34b",
+			},
+			workspacePath: "test-data/",
+			quiet:         true,
+			sleep:         func(time.Duration) {},
+		}
+		err := mvn.Publish()
+		server.Close()
+		var eerr *existsError
+		if ok := errors.As(err, &eerr); ok != released {
+			t.Fatalf("released %v: unexpected result %v", released, err)
+		}
+	}
+
+	mvn := &Maven{
+		Repository: Repository{
+			Username:    "u",
+			Password:    "p",
+			SnapshotURL: "http://localhost/content/repositories/snapshots",
+		},
+		Args: Args{
+			Source:     Patterns{"single/release.zip"},
+			Backend:    BackendNative,
+			OnExisting: OnExistingSkip,
+		},
+		Staging: Staging{
+			URL:       "http://localhost",
+			ProfileID: "12a34b",
+		},
+		workspacePath: "test-data/",
+		quiet:         true,
+	}
+	err := mvn.Publish()
+	if err == nil || !strings.Contains(err.Error(), "requires the url or release_url") {
+		t.Fatalf("expected release repository error, got %v", err)
+	}
+}
@@ -0,0 +1,213 @@
+package mavendeploy
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// glob returns the files matching the workspace relative pattern. Braces are
+// expanded first, e.g. *.{zip,tar.gz} matches both *.zip and *.tar.gz.
+//
+// Patterns containing ** are matched by walking the workspace where **
+// matches any number of directories. The walk skips hidden files and
+// directories unless a pattern element starts with a dot or hidden files are
+// enabled, and it only follows symlinked directories if enabled. Other
+// patterns are matched using filepath.Glob.
+func (mvn *Maven) glob(pattern string) ([]string, error) {
+	var matches []string
+	seen := make(map[string]bool)
+	for _, p := range expandBraces(pattern) {
+		var found []string
+		var err error
+		if strings.Contains(p, "**") {
+			w := &walker{
+				hidden:  mvn.Args.Hidden,
+				follow:  mvn.Args.FollowSymlinks,
+				visited: make(map[string]bool),
+			}
+			err = w.match(filepath.Clean(mvn.workspacePath), strings.Split(filepath.ToSlash(p), "/"))
+			found = w.matches
+			sort.Strings(found)
+		} else {
+			found, err = filepath.Glob(mvn.workspacePath + string(os.PathSeparator) + p)
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range found {
+			// patterns without wildcards are returned as given, clean them
+			// so that a file matched by several patterns has one name.
+			v = filepath.Clean(v)
+			if !seen[v] {
+				seen[v] = true
+				matches = append(matches, v)
+			}
+		}
+	}
+	return matches, nil
+}
+
+// walker matches the elements of a ** pattern against a directory tree.
+type walker struct {
+	hidden  bool            // match hidden files and directories
+	follow  bool            // descend into symlinked directories
+	visited map[string]bool // directories descended by ** by real path
+	matches []string
+}
+
+func (w *walker) match(dir string, elems []string) error {
+	if len(elems) == 0 {
+		return nil
+	}
+	elem, rest := elems[0], elems[1:]
+	switch {
+	case elem == "" || elem == ".":
+		return w.match(dir, rest)
+	case elem == "**":
+		real, err := filepath.EvalSymlinks(dir)
+		if err != nil {
+			return err
+		}
+		if w.visited[real+"\x00"+strings.Join(rest, "/")] {
+			return nil
+		}
+		w.visited[real+"\x00"+strings.Join(rest, "/")] = true
+		if len(rest) == 0 {
+			// a trailing ** matches all files below dir
+			rest = []string{"*"}
+		}
+		err = w.match(dir, rest)
+		if err != nil {
+			return err
+		}
+		entries, err := w.readDir(dir)
+		if err != nil {
+			return err
+		}
+		for _, v := range entries {
+			if !w.isDir(dir, v) || !w.visible(v.Name(), elem) {
+				continue
+			}
+			err := w.match(filepath.Join(dir, v.Name()), elems)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if !hasMeta(elem) {
+		name := filepath.Join(dir, elem)
+		fi, err := os.Stat(name)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return w.add(name, fi.IsDir(), rest)
+	}
+	entries, err := w.readDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, v := range entries {
+		ok, err := filepath.Match(elem, v.Name())
+		if err != nil {
+			return err
+		}
+		if !ok || !w.visible(v.Name(), elem) {
+			continue
+		}
+		err = w.add(filepath.Join(dir, v.Name()), w.isDir(dir, v), rest)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// add adds name as a match if it is the last pattern element and a file,
+// otherwise the rest of the pattern is matched in it.
+func (w *walker) add(name string, isDir bool, rest []string) error {
+	if len(rest) == 0 {
+		if !isDir {
+			w.matches = append(w.matches, name)
+		}
+		return nil
+	}
+	if !isDir {
+		return nil
+	}
+	return w.match(name, rest)
+}
+
+func (w *walker) readDir(dir string) ([]os.FileInfo, error) {
+	f, err := os.Open(dir)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return f.Readdir(-1)
+}
+
+// isDir returns true for directories and, if symlinks are followed,
+// symlinks to directories.
+func (w *walker) isDir(dir string, fi os.FileInfo) bool {
+	if fi.Mode()&os.ModeSymlink == 0 {
+		return fi.IsDir()
+	}
+	if !w.follow {
+		return false
+	}
+	target, err := os.Stat(filepath.Join(dir, fi.Name()))
+	return err == nil && target.IsDir()
+}
+
+// visible returns false for hidden names unless hidden files are enabled or
+// the pattern element explicitly starts with a dot.
+func (w *walker) visible(name, elem string) bool {
+	return w.hidden || !strings.HasPrefix(name, ".") || strings.HasPrefix(elem, ".")
+}
+
+func hasMeta(s string) bool {
+	return strings.ContainsAny(s, `*?[\`)
+}
+
+// expandBraces returns the patterns described by the brace expressions in
+// pattern, e.g. a.{zip,tar.{gz,xz}} expands to a.zip, a.tar.gz and a.tar.xz.
+// Unbalanced braces are kept as is.
+func expandBraces(pattern string) []string {
+	start := strings.Index(pattern, "{")
+	if start < 0 {
+		return []string{pattern}
+	}
+	depth := 0
+	var alternatives []string
+	last := start + 1
+	for i := start; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '{':
+			depth++
+		case ',':
+			if depth == 1 {
+				alternatives = append(alternatives, pattern[last:i])
+				last = i + 1
+			}
+		case '}':
+			depth--
+			if depth > 0 {
+				continue
+			}
+			alternatives = append(alternatives, pattern[last:i])
+			prefix, suffix := pattern[:start], pattern[i+1:]
+			var expanded []string
+			for _, a := range alternatives {
+				expanded = append(expanded, expandBraces(prefix+a+suffix)...)
+			}
+			return expanded
+		}
+	}
+	return []string{pattern}
+}
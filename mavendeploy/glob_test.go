@@ -0,0 +1,153 @@
+package mavendeploy
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExpandBraces(t *testing.T) {
+	for _, v := range []struct {
+		pattern  string
+		expected []string
+	}{
+		{"dist/*.zip", []string{"dist/*.zip"}},
+		{"dist/*.{zip,tar.gz}", []string{"dist/*.zip", "dist/*.tar.gz"}},
+		{"{a,b}/*.{zip,tar.{gz,xz}}", []string{"a/*.zip", "a/*.tar.gz", "a/*.tar.xz", "b/*.zip", "b/*.tar.gz", "b/*.tar.xz"}},
+		{"dist/*.{zip", []string{"dist/*.{zip"}},
+	} {
+		if expanded := expandBraces(v.pattern); !reflect.DeepEqual(expanded, v.expected) {
+			t.Errorf("expected %v for %s, got %v", v.expected, v.pattern, expanded)
+		}
+	}
+}
+
+// globTree creates a workspace with nested modules, hidden files and
+// symlinked directories.
+func globTree(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "drone-mvn-glob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{
+		"top-0.1.zip",
+		".dot-0.1.zip",
+		"modules/a/build/dist/a-1.0.zip",
+		"modules/a/build/dist/a-1.0.tar.gz",
+		"modules/a/build/dist/a-1.0.txt",
+		"modules/b/build/dist/b-2.0.zip",
+		"modules/.hidden/build/dist/h-1.0.zip",
+		"other/c/build/dist/c-3.0.zip",
+	} {
+		name := filepath.Join(dir, filepath.FromSlash(v))
+		err := os.MkdirAll(filepath.Dir(name), 0755)
+		if err == nil {
+			err = ioutil.WriteFile(name, []byte("1\n"), 0644)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	for link, target := range map[string]string{
+		"modules/c":    "../other/c",
+		"modules/loop": "..",
+	} {
+		err := os.Symlink(target, filepath.Join(dir, filepath.FromSlash(link)))
+		if err != nil {
+			t.Skip("symlinks not supported:", err)
+		}
+	}
+	return dir
+}
+
+func TestGlob(t *testing.T) {
+	dir := globTree(t)
+	defer os.RemoveAll(dir)
+	for _, v := range []struct {
+		pattern  string
+		hidden   bool
+		follow   bool
+		expected []string
+	}{
+		{"modules/**/build/dist/*.{zip,tar.gz}", false, false, []string{
+			"modules/a/build/dist/a-1.0.zip",
+			"modules/b/build/dist/b-2.0.zip",
+			"modules/a/build/dist/a-1.0.tar.gz",
+		}},
+		{"modules/**/*.zip", true, false, []string{
+			"modules/.hidden/build/dist/h-1.0.zip",
+			"modules/a/build/dist/a-1.0.zip",
+			"modules/b/build/dist/b-2.0.zip",
+		}},
+		{"modules/**/*.zip", false, true, []string{
+			"modules/a/build/dist/a-1.0.zip",
+			"modules/b/build/dist/b-2.0.zip",
+			"modules/c/build/dist/c-3.0.zip",
+			"modules/loop/top-0.1.zip", // directories are only searched once
+		}},
+		{"**/*.zip", false, false, []string{
+			"modules/a/build/dist/a-1.0.zip",
+			"modules/b/build/dist/b-2.0.zip",
+			"other/c/build/dist/c-3.0.zip",
+			"top-0.1.zip",
+		}},
+		{"modules/.hidden/**", false, false, []string{
+			"modules/.hidden/build/dist/h-1.0.zip",
+		}},
+		{"**/.dot-*", false, false, []string{
+			".dot-0.1.zip",
+		}},
+		{"*.zip", false, false, []string{
+			".dot-0.1.zip",
+			"top-0.1.zip",
+		}},
+	} {
+		mvn := &Maven{
+			Args: Args{
+				Hidden:         v.hidden,
+				FollowSymlinks: v.follow,
+			},
+			workspacePath: dir,
+		}
+		matches, err := mvn.glob(v.pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var rel []string
+		for _, m := range matches {
+			rel = append(rel, filepath.ToSlash(mvn.relPath(m)))
+		}
+		if !reflect.DeepEqual(rel, v.expected) {
+			t.Errorf("%+v: got %v", v, rel)
+		}
+	}
+}
+
+func TestPrepareNested(t *testing.T) {
+	dir := globTree(t)
+	defer os.RemoveAll(dir)
+	mvn := &Maven{
+		Artifact: Artifact{
+			GroupID: "com.test.glob",
+		},
+		Args: Args{
+			Source: Patterns{"modules/**/build/dist/*.{zip,tar.gz}"},
+			Regexp: `^modules/(?P<artifact>[^/]+)/build/dist/[^/]+-(?P<version>[0-9.]+)\.(?P<extension>zip|tar\.gz)$`,
+		},
+		workspacePath: dir,
+		quiet:         true,
+	}
+	err := mvn.Prepare()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"com.test.glob:a:1.0", "com.test.glob:b:2.0"}
+	if keys := mvn.artifactKeys(); !reflect.DeepEqual(keys, expected) {
+		t.Fatalf("expected %v, got %v", expected, keys)
+	}
+	if n := len(mvn.artifacts["com.test.glob:a:1.0"]); n != 2 {
+		t.Fatalf("expected 2 artifacts of a, got %d", n)
+	}
+}
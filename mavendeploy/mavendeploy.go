@@ -1,21 +1,24 @@
 package mavendeploy
 
 import (
+	"bytes"
 	"encoding/xml"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
-	"regexp"
 	"strings"
+	"time"
 
 	"github.com/davecgh/go-spew/spew"
+	"github.com/drone/drone-plugin-go/plugin"
 )
 
 const (
-	mavenGpg    = "org.apache.maven.plugins:maven-gpg-plugin:1.6:sign-and-deploy-file"
 	mavenDeploy = "org.apache.maven.plugins:maven-deploy-plugin:2.8.2:deploy-file"
 )
 
@@ -27,18 +30,65 @@ type Maven struct {
 	GPG        // signing information
 	Args       // drone-mvn specific options
 
-	gpgCmd        *GpgCmd
+	POM     POM     `json:"pom"`     // generated pom.xml information
+	Staging Staging `json:"staging"` // nexus staging of releases
+	Central Central `json:"central"` // central portal publishing
+
+	Artifactory Artifactory `json:"artifactory"` // artifactory build info
+
+	signer        *Signer
+	signatures    map[string]string // signature files for mvn by signed file
 	workspacePath string
 	settingsPath  string
+	pomFiles      map[string]string   // pom files by artifact key
+	projects      map[string]*Project // generated poms by artifact key
 	artifacts     map[string][]Artifact
+	properties    map[string]string
+	deployment    *deployment  // results of the last publish
+	log           io.Writer    // log output, os.Stdout if nil
+	output        io.Writer    // dry run output, os.Stdout if nil
+	build         plugin.Build // coordinate template data
+	repo          plugin.Repo  // coordinate template data
 	quiet         bool
+	clock         func() time.Time    // overrides time.Now in tests
+	sleep         func(time.Duration) // overrides time.Sleep in tests
 }
 
 // Repository is a target Maven repository configuration
+//
+// SnapshotURL and ReleaseURL are used for snapshot and release versions
+// respectively and URL for any version without a dedicated repository.
 type Repository struct {
-	Username string `json:"username"`
-	Password string `json:"password"`
-	URL      string `json:"url"`
+	Username    string `json:"username"`
+	Password    string `json:"password"`
+	URL         string `json:"url"`
+	SnapshotURL string `json:"snapshot_url"`
+	ReleaseURL  string `json:"release_url"`
+}
+
+// forVersion returns the repository with URL set to the repository used for
+// version. Snapshots are never deployed to the release repository and
+// releases never to the snapshot repository.
+func (r Repository) forVersion(version string) (Repository, error) {
+	target, other, kind := r.ReleaseURL, r.SnapshotURL, "release"
+	if isSnapshot(version) {
+		target, other, kind = r.SnapshotURL, r.ReleaseURL, "snapshot"
+	}
+	switch {
+	case target != "":
+		r.URL = target
+	case r.URL == "" && other != "":
+		return r, fmt.Errorf("refusing to deploy %s version %s to %s, no %s repository is configured",
+			kind, version, other, kind)
+	case r.URL == "":
+		return r, fmt.Errorf("repository url is %s", errRequiredValue)
+	}
+	return r, nil
+}
+
+// isConfigured returns true if any repository url is set.
+func (r Repository) isConfigured() bool {
+	return r.URL != "" || r.SnapshotURL != "" || r.ReleaseURL != ""
 }
 
 // Artifact is a target Maven artifact.
@@ -49,14 +99,55 @@ type Artifact struct {
 	Classifier string `json:"classifier"` // e.g. sources, javadoc, <the empty string>...
 	Extension  string `json:"extension"`  // e.g. jar, .tar.gz, .zip
 	file       string
+
+	uniqueVersion string // timestamped snapshot version used in file names
+}
+
+// POM is the descriptive information written to the generated pom.xml of
+// each published groupID:artifactID:version. The values are used as defaults
+// for all artifacts and can be overridden per artifact in Artifacts which is
+// keyed by either artifact, group:artifact or group:artifact:version.
+type POM struct {
+	Name            string           `json:"name"`
+	Description     string           `json:"description"`
+	URL             string           `json:"url"`
+	Organization    *Organization    `json:"organization"`
+	Licenses        []License        `json:"licenses"`
+	Developers      []Developer      `json:"developers"`
+	SCM             *SCM             `json:"scm"`
+	IssueManagement *IssueManagement `json:"issue_management"`
+	Dependencies    []Dependency     `json:"dependencies"`
+	Artifacts       map[string]POM   `json:"artifacts"` // per artifact overrides
 }
 
 // Args is the drone-mvn specific arguments.
 // If there are multiple matches to Source, ArtifactRegexp must be defined.
 type Args struct {
-	Source string `json:"source"` // artifact filename glob
-	Regexp string `json:"regexp"` // parses artifact filenames to artifacts
-	Debug  bool   `json:"debug"`  // debug output
+	Source  Patterns `json:"source"`  // artifact filename globs
+	Exclude Patterns `json:"exclude"` // filename globs excluded from source
+	Rules   []Rule   `json:"rules"`   // sources mapped to artifacts, before source
+
+	Regexp  string `json:"regexp"`   // parses artifact filenames to artifacts
+	Pattern string `json:"pattern"`  // alternative to regexp, e.g. {artifact}-{version}.{extension}
+	Debug   bool   `json:"debug"`    // debug output
+	Backend string `json:"backend"`  // deploy backend, mvn (default), native or central
+	PomFile string `json:"pom_file"` // pom.xml to publish instead of a generated one
+
+	Hidden         bool `json:"hidden"`          // match hidden files with ** patterns
+	FollowSymlinks bool `json:"follow_symlinks"` // descend into symlinked directories with ** patterns
+
+	Checksums []string `json:"checksums"` // checksum files, md5 and sha1 (default), sha256, sha512
+	DryRun    bool     `json:"dry_run"`   // print what would be deployed without deploying
+	Report    string   `json:"report"`    // path of a json report of the deployed files
+	Retry     Retry    `json:"retry"`     // retries of failed deployments
+
+	Concurrency int    `json:"concurrency"` // artifact groups deployed in parallel, default 1
+	OnExisting  string `json:"on_existing"` // policy for release versions already in the repository
+
+	Properties map[string]string `json:"properties"` // artifactory properties of the uploaded files
+
+	CentralChecks     bool     `json:"central_checks"`     // validate against the maven central requirements in Prepare
+	CentralNamespaces []string `json:"central_namespaces"` // verified namespaces required by central checks
 }
 
 // GPG holds the GnuPG key information used for signing releases.
@@ -65,6 +156,13 @@ type GPG struct {
 	Passphrase string `json:"gpg_passphrase"`  // private key passphrase (optional)
 }
 
+// Deploy backends.
+const (
+	BackendMvn     = "mvn"     // maven-deploy-plugin using the mvn command
+	BackendNative  = "native"  // built in maven repository layout writer
+	BackendCentral = "central" // maven central publisher portal bundle
+)
+
 var (
 	errRequiredValue = errors.New("required")
 	errInvalidValue  = errors.New("invalid")
@@ -82,145 +180,183 @@ func (mvn *Maven) Publish() error {
 	}
 	// skip if Repository Username or Password are empty. A good example for
 	// this would be forks building a project.
-	if mvn.Repository.Username == "" || mvn.Repository.Password == "" {
+	if !mvn.Args.DryRun && (mvn.Repository.Username == "" || mvn.Repository.Password == "") {
 		mvn.infof("username or password is empty, skipping publish")
 		return nil
 	}
-	if mvn.Repository.URL == "" {
+	if !mvn.hasTarget() {
 		mvn.infof("URL is not set")
 		return errRequiredValue
 	}
+	switch mvn.Args.Backend {
+	case "", BackendMvn, BackendNative, BackendCentral:
+	default:
+		mvn.infof("unknown backend %s", mvn.Args.Backend)
+		return errInvalidValue
+	}
+	err := mvn.validateChecksums()
+	if err != nil {
+		return err
+	}
+	err = mvn.Args.Retry.validate()
+	if err != nil {
+		return err
+	}
+	err = mvn.Staging.validate()
+	if err != nil {
+		return err
+	}
+	err = mvn.validateCentral()
+	if err != nil {
+		return err
+	}
+	err = mvn.Artifactory.validate()
+	if err != nil {
+		return err
+	}
+	switch mvn.Args.OnExisting {
+	case "", OnExistingOverwrite, OnExistingFail, OnExistingSkip, OnExistingSkipIfIdentical:
+	default:
+		mvn.infof("unknown on_existing policy %s", mvn.Args.OnExisting)
+		return errInvalidValue
+	}
+	if mvn.Args.OnExisting != "" && mvn.Args.OnExisting != OnExistingOverwrite &&
+		mvn.Staging.enabled() && mvn.Repository.URL == "" && mvn.Repository.ReleaseURL == "" {
+		return fmt.Errorf("on_existing %s with staging requires the url or release_url of the release repository",
+			mvn.Args.OnExisting)
+	}
 
-	err := mvn.Prepare()
+	err = mvn.Prepare()
 	if err != nil {
 		return err
 	}
+	for _, key := range mvn.artifactKeys() {
+		_, err := mvn.repository(mvn.artifacts[key][0].Version)
+		if err != nil {
+			return err
+		}
+	}
+	mvn.signer = nil
 	if mvn.GPG.PrivateKey != "" {
-		gpgCmd := &GpgCmd{GPG: mvn.GPG}
-		err := gpgCmd.Setup()
+		signer, err := NewSigner(mvn.GPG)
 		if err != nil {
 			return err
 		}
-		defer func() {
-			err := gpgCmd.Teardown()
-			if err != nil {
-				panic(err)
-			}
-		}()
-		mvn.gpgCmd = gpgCmd
+		mvn.infof("signing with gpg key %s", signer.KeyID())
+		mvn.signer = signer
 	}
+	mvn.deployment = newDeployment()
+	switch {
+	case mvn.Args.DryRun:
+		err = mvn.dryRun()
+		if err == nil && (mvn.Args.Backend == "" || mvn.Args.Backend == BackendMvn) {
+			err = mvn.dryRunMvn()
+		}
+	case mvn.Staging.enabled():
+		err = mvn.publishStaged()
+	default:
+		err = mvn.publishBackend()
+	}
+	if err != nil {
+		return err
+	}
+	err = mvn.writeReport()
+	if err != nil || !mvn.Artifactory.BuildInfo {
+		return err
+	}
+	return mvn.publishBuildInfo()
+}
+
+// publishBackend deploys the prepared artifacts using the configured backend.
+func (mvn *Maven) publishBackend() error {
+	switch mvn.Args.Backend {
+	case BackendNative:
+		return mvn.publishNative()
+	case BackendCentral:
+		return mvn.publishCentral()
+	}
+	return mvn.publishMvn()
+}
+
+// hasTarget returns true if there is somewhere to deploy to.
+func (mvn *Maven) hasTarget() bool {
+	return mvn.Repository.isConfigured() || mvn.Staging.enabled() || mvn.Args.Backend == BackendCentral
+}
+
+// publishMvn deploys the prepared artifacts using the mvn command.
+func (mvn *Maven) publishMvn() error {
 	settings, err := m2Settings(*mvn)
 	if err != nil {
 		return err
 	}
 
 	mvn.settingsPath = settings
-	if !mvn.quiet {
-		fmt.Println("$", settings)
-	}
+	mvn.infof("%s", settings)
 	defer func() {
 
 		os.Remove(settings)
 	}()
-	var commands []*exec.Cmd
-	for _, v := range mvn.artifacts {
-		cmd := mvn.command(v...)
-		cmd.Env = os.Environ()
-		if !mvn.quiet {
-			cmd.Stdout = os.Stdout
-			cmd.Stderr = os.Stderr
+	dir, err := ioutil.TempDir("", "drone-mvn")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	if mvn.Args.PomFile == "" {
+		err := mvn.writePoms(dir)
+		if err != nil {
+			return err
 		}
-		commands = append(commands, cmd)
 	}
-	for _, cmd := range commands {
-		if !mvn.quiet {
-			mvn.trace(cmd)
+	mvn.signatures = nil
+	if mvn.signer != nil {
+		err := mvn.signFiles(dir)
+		if err != nil {
+			return err
 		}
-		err = cmd.Run()
+	}
+	return mvn.forEachGroup(func(g *Maven, key string) error {
+		artifacts := g.artifacts[key]
+		t, err := g.transport(artifacts[0])
 		if err != nil {
 			return err
 		}
+		skip, err := g.checkExisting(t, key)
+		if err != nil || skip {
+			return err
+		}
+		return g.retry(key, func() error {
+			return g.run(g.command(artifacts...))
+		})
+	})
+}
+
+// run runs a mvn command, the output is kept in the returned error to be
+// able to tell if a failed deployment can be retried.
+func (mvn *Maven) run(cmd *exec.Cmd) error {
+	var output bytes.Buffer
+	cmd.Env = os.Environ()
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if !mvn.quiet {
+		cmd.Stdout = io.MultiWriter(mvn.logWriter(), &output)
+		cmd.Stderr = cmd.Stdout
+	}
+	mvn.trace(cmd)
+	err := cmd.Run()
+	if err != nil {
+		return &mvnError{err: err, output: output.String()}
 	}
 	return nil
 }
 
 func (mvn *Maven) Prepare() error {
-	sources, err := filepath.Glob(mvn.workspacePath + string(os.PathSeparator) + mvn.Args.Source)
+	parsed, captures, err := mvn.parseSources()
 	if err != nil {
 		return err
 	}
-	if len(sources) == 0 {
-		return fmt.Errorf("no sources found for %s ", mvn.Args.Source)
-	}
-	if mvn.Args.Debug {
-		fmt.Println("sources found:")
-		spew.Dump(sources)
-	}
-	if len(sources) > 1 {
-		if mvn.Args.Regexp == "" {
-			return fmt.Errorf(
-				"multiple sources found for %s (%v) but no regexp was defined",
-				mvn.Args.Source, sources)
-		}
-	}
-
-	var parsed []Artifact
-	if mvn.Args.Regexp == "" {
-		a := mvn.Artifact
-		a.file = sources[0]
-		parsed = append(parsed, a)
-	} else {
-		re, err := regexp.Compile(mvn.Args.Regexp)
-		if err != nil {
-			return err
-		}
-		for _, s := range sources {
-			rel, err := filepath.Rel(mvn.workspacePath, s)
-			if err != nil {
-				fmt.Printf("could not make source %s relative to %s\n", s, mvn.workspacePath)
-				return err
-			}
-			matches := re.FindStringSubmatch(rel)
-			if matches == nil {
-				return fmt.Errorf("regexp '%s' does not match '%s'", mvn.Args.Regexp, s)
-			}
-			var a Artifact
-			for i, name := range re.SubexpNames() {
-				v := matches[i]
-				switch name {
-				case "":
-					continue
-				case "version":
-					a.Version = v
-				case "classifier":
-					a.Classifier = v
-				case "artifact":
-					a.ArtifactID = v
-				case "group":
-					a.GroupID = v
-				case "extension":
-					a.Extension = v
-				default:
-					return fmt.Errorf("key %s not recognized by drone-mvn", name)
-				}
-			}
-			a.file = s
-			parsed = append(parsed, a)
-			if mvn.Args.Debug {
-				fmt.Println("$ parsed artifact")
-				spew.Dump(a)
-			}
-		}
-		if len(parsed) == 0 {
-			return errNotFound
-		}
-	}
 
 	// partition parsed artifacts into a map
 	mapped := make(map[string][]Artifact, 0)
-	mapkey := func(a Artifact) string {
-		return fmt.Sprintf("%s:%s:%s", a.GroupID, a.ArtifactID, a.Version)
-	}
 	fill := func(orig Artifact) Artifact {
 		a := orig
 		if a.GroupID == "" {
@@ -240,9 +376,21 @@ func (mvn *Maven) Prepare() error {
 		}
 		return a
 	}
-	for _, v := range parsed {
-		filled := fill(v)
-		key := mapkey(filled)
+	deployedFrom := make(map[string]string) // source file by repository path
+	for i, v := range parsed {
+		filled, err := mvn.expandTemplates(fill(v), captures[i])
+		if err != nil {
+			return err
+		}
+		// sources of different rules or with unused captures may end up
+		// with the same coordinates and overwrite each other.
+		p := path.Join(filled.versionDir(), filled.fileName())
+		if prev, ok := deployedFrom[p]; ok {
+			return fmt.Errorf("sources %s and %s are both deployed as %s",
+				mvn.relPath(prev), mvn.relPath(filled.file), p)
+		}
+		deployedFrom[p] = filled.file
+		key := filled.key()
 		var artifacts []Artifact
 		if _, ok := mapped[key]; ok {
 			artifacts = mapped[key]
@@ -261,9 +409,53 @@ func (mvn *Maven) Prepare() error {
 		spew.Dump(mapped)
 	}
 
+	mvn.pomFiles = nil
+	mvn.projects = nil
+	if mvn.Args.PomFile != "" {
+		err = mvn.preparePomFiles()
+	} else {
+		err = mvn.preparePoms()
+	}
+	if err != nil {
+		return err
+	}
+	err = mvn.prepareProperties()
+	if err != nil {
+		return err
+	}
+	if mvn.Args.CentralChecks {
+		return mvn.checkCentral()
+	}
 	return nil
 }
 
+// dryRunMvn traces the mvn commands of the prepared artifacts. The settings,
+// poms and signatures are not written, the commands refer to the files they
+// would be written to instead.
+func (mvn *Maven) dryRunMvn() error {
+	mvn.settingsPath = "settings.xml"
+	if mvn.Args.PomFile == "" {
+		mvn.pomFiles = make(map[string]string, len(mvn.artifacts))
+		for key, artifacts := range mvn.artifacts {
+			mvn.pomFiles[key] = artifacts[0].GroupID + "-" + artifacts[0].pomName()
+		}
+	}
+	mvn.signatures = nil
+	if mvn.signer != nil {
+		mvn.signatures = make(map[string]string)
+		for key, artifacts := range mvn.artifacts {
+			mvn.signatures[mvn.pomFiles[key]] = mvn.pomFiles[key] + ".asc"
+			for _, v := range artifacts {
+				mvn.signatures[v.file] = v.file + ".asc"
+			}
+		}
+	}
+	return mvn.forEachGroup(func(g *Maven, key string) error {
+		g.trace(g.command(g.artifacts[key]...))
+		return nil
+	})
+}
+
 // command is a helper function that returns the command
 // and arguments to upload to aws from the command line.
 func (mvn Maven) command(artifacts ...Artifact) *exec.Cmd {
@@ -281,24 +473,12 @@ func (mvn Maven) command(artifacts ...Artifact) *exec.Cmd {
 	args = append(args,
 		"--settings", mvn.settingsPath,
 	)
-	if mvn.gpgCmd != nil {
-
-		args = append(args,
-			mavenGpg,
-			fmt.Sprintf("-Dgpg.defaultKeyring=false"),
-			fmt.Sprintf("-Dgpg.publicKeyring=%s", mvn.gpgCmd.PublicRing),
-			fmt.Sprintf("-Dgpg.secretKeyring=%s", mvn.gpgCmd.SecretRing),
-			fmt.Sprintf("-Dgpg.keyname=%s", mvn.gpgCmd.SecretKeyID),
-			fmt.Sprintf("-Dgpg.passphraseServerId=%s", gpgServerID),
-			fmt.Sprintf("-Dgpg.ascDirectory=%s", mvn.gpgCmd.tempDir),
-		)
-	} else {
-		args = append(args, mavenDeploy)
-	}
+	args = append(args, mavenDeploy)
 
 	a := artifacts[0]
+	repo, _ := mvn.repository(a.Version)
 	args = append(args,
-		fmt.Sprintf("-Durl=%s", mvn.Repository.URL),
+		fmt.Sprintf("-Durl=%s%s", repo.URL, mvn.matrixParams()),
 		fmt.Sprintf("-DrepositoryId=%s", deployRepoID),
 		fmt.Sprintf("-DgroupId=%s", a.GroupID),
 		fmt.Sprintf("-DartifactId=%s", a.ArtifactID),
@@ -311,14 +491,28 @@ func (mvn Maven) command(artifacts ...Artifact) *exec.Cmd {
 	if a.Classifier != "" {
 		args = append(args, fmt.Sprintf("-Dclassifier=%s", a.Classifier))
 	}
-
-	if len(artifacts) > 1 {
-		var files, types, classifiers []string
-		for _, v := range artifacts[1:] {
-			files = append(files, v.file)
-			types = append(types, v.Extension)
+	pomFile := mvn.pomFiles[a.key()]
+	args = append(args, fmt.Sprintf("-DpomFile=%s", pomFile))
+
+	var files, types, classifiers []string
+	for _, v := range artifacts[1:] {
+		files = append(files, v.file)
+		types = append(types, v.Extension)
+		classifiers = append(classifiers, v.Classifier)
+	}
+	// signatures are attached as additional files using the signed file type
+	// with an .asc suffix, like maven-gpg-plugin does.
+	if mvn.signatures != nil {
+		for _, v := range artifacts {
+			files = append(files, mvn.signatures[v.file])
+			types = append(types, v.extension()+".asc")
 			classifiers = append(classifiers, v.Classifier)
 		}
+		files = append(files, mvn.signatures[pomFile])
+		types = append(types, "pom.asc")
+		classifiers = append(classifiers, "")
+	}
+	if len(files) > 0 {
 		args = append(args, fmt.Sprintf("-Dfiles=%s", strings.Join(files, ",")))
 		args = append(args, fmt.Sprintf("-Dclassifiers=%s", strings.Join(classifiers, ",")))
 		args = append(args, fmt.Sprintf("-Dtypes=%s", strings.Join(types, ",")))
@@ -334,10 +528,9 @@ type Settings struct {
 
 // Server entry for the maven settings.xml
 type Server struct {
-	ID         string `xml:"id"`
-	Username   string `xml:"username,omitempty"`
-	Password   string `xml:"password,omitempty"`
-	Passphrase string `xml:"passphrase,omitempty"`
+	ID       string `xml:"id"`
+	Username string `xml:"username,omitempty"`
+	Password string `xml:"password,omitempty"`
 }
 
 func m2Settings(m Maven) (string, error) {
@@ -347,12 +540,6 @@ func m2Settings(m Maven) (string, error) {
 		Username: m.Repository.Username,
 		Password: m.Repository.Password,
 	})
-	if m.GPG.PrivateKey != "" {
-		servers = append(servers, Server{
-			ID:         gpgServerID,
-			Passphrase: m.GPG.Passphrase,
-		})
-	}
 	settings := Settings{
 		Servers: servers,
 	}
@@ -375,22 +562,72 @@ func m2Settings(m Maven) (string, error) {
 
 }
 
+// writePoms writes the generated pom of each artifact group to dir.
+func (mvn *Maven) writePoms(dir string) error {
+	mvn.pomFiles = make(map[string]string, len(mvn.artifacts))
+	for key, artifacts := range mvn.artifacts {
+		a := artifacts[0]
+		data, err := mvn.projects[key].Marshal()
+		if err != nil {
+			return err
+		}
+		filename := filepath.Join(dir, a.GroupID+"-"+a.pomName())
+		err = ioutil.WriteFile(filename, data, 0644)
+		if err != nil {
+			return err
+		}
+		mvn.pomFiles[key] = filename
+	}
+	return nil
+}
+
+// signFiles writes a signature of each artifact file and pom to dir.
+func (mvn *Maven) signFiles(dir string) error {
+	mvn.signatures = make(map[string]string)
+	for key, artifacts := range mvn.artifacts {
+		filenames := []string{mvn.pomFiles[key]}
+		for _, v := range artifacts {
+			filenames = append(filenames, v.file)
+		}
+		for _, filename := range filenames {
+			if _, ok := mvn.signatures[filename]; ok {
+				continue
+			}
+			f, err := os.Open(filename)
+			if err != nil {
+				return err
+			}
+			signature, err := mvn.signer.Sign(f)
+			f.Close()
+			if err != nil {
+				return err
+			}
+			asc := filepath.Join(dir, fmt.Sprintf("%d.asc", len(mvn.signatures)))
+			err = ioutil.WriteFile(asc, signature, 0644)
+			if err != nil {
+				return err
+			}
+			mvn.signatures[filename] = asc
+		}
+	}
+	return nil
+}
+
 // trace writes each command to standard error (preceded by a ‘$ ’) before it
 // is executed. Used for debugging your build.
 func (mvn *Maven) trace(cmd *exec.Cmd) {
 	if !mvn.quiet {
-		fmt.Println("$", strings.Join(cmd.Args, " "))
+		fmt.Fprintln(mvn.logWriter(), "$", strings.Join(cmd.Args, " "))
 	}
 }
 
 func (mvn *Maven) infof(format string, a ...interface{}) {
 	if !mvn.quiet {
-		fmt.Println("$", fmt.Sprintf(format, a...))
+		fmt.Fprintln(mvn.logWriter(), "$", fmt.Sprintf(format, a...))
 	}
 }
 
-// static id's for maven repo id and gpg auth info generation.
+// static id for maven repo id generation.
 const (
 	deployRepoID = "deploy-repo"
-	gpgServerID  = "gpg-auth"
 )
@@ -61,7 +61,7 @@ func TestPublish1(t *testing.T) {
 			},
 			GPG: GPG{},
 			Args: Args{
-				Source: "multiple-matched/app*",
+				Source: Patterns{"multiple-matched/app*"},
 				Regexp: "(?P<artifact>app-[^/-]*)-(?P<classifier>[^-]*-[^-]*)-(?P<version>.*).(?P<extension>tar.gz|zip|readme)$",
 			}}}
 
@@ -71,53 +71,57 @@ func TestPublish1(t *testing.T) {
 		if err != nil {
 			t.Fatal(err)
 		}
-		l.AssertFiles(
-			"com/test/publish1/app-client/0.1.4/app-client-0.1.4-darwin-amd64.zip",
-			"com/test/publish1/app-client/0.1.4/app-client-0.1.4-darwin-amd64.zip.md5",
-			"com/test/publish1/app-client/0.1.4/app-client-0.1.4-darwin-amd64.zip.sha1",
-			"com/test/publish1/app-client/0.1.4/app-client-0.1.4-linux-386.tar.gz",
-			"com/test/publish1/app-client/0.1.4/app-client-0.1.4-linux-386.tar.gz.md5",
-			"com/test/publish1/app-client/0.1.4/app-client-0.1.4-linux-386.tar.gz.sha1",
-			"com/test/publish1/app-client/0.1.4/app-client-0.1.4-linux-amd64.tar.gz",
-			"com/test/publish1/app-client/0.1.4/app-client-0.1.4-linux-amd64.tar.gz.md5",
-			"com/test/publish1/app-client/0.1.4/app-client-0.1.4-linux-amd64.tar.gz.sha1",
-			"com/test/publish1/app-client/0.1.4/app-client-0.1.4-windows-386.zip",
-			"com/test/publish1/app-client/0.1.4/app-client-0.1.4-windows-386.zip.md5",
-			"com/test/publish1/app-client/0.1.4/app-client-0.1.4-windows-386.zip.sha1",
-			"com/test/publish1/app-client/0.1.4/app-client-0.1.4-windows-amd64.zip",
-			"com/test/publish1/app-client/0.1.4/app-client-0.1.4-windows-amd64.zip.md5",
-			"com/test/publish1/app-client/0.1.4/app-client-0.1.4-windows-amd64.zip.sha1",
-			"com/test/publish1/app-client/0.1.4/app-client-0.1.4.pom",
-			"com/test/publish1/app-client/0.1.4/app-client-0.1.4.pom.md5",
-			"com/test/publish1/app-client/0.1.4/app-client-0.1.4.pom.sha1",
-			"com/test/publish1/app-client/maven-metadata.xml",
-			"com/test/publish1/app-client/maven-metadata.xml.md5",
-			"com/test/publish1/app-client/maven-metadata.xml.sha1",
-			"com/test/publish1/app-gui/0.1.4/app-gui-0.1.4-darwin-amd64.zip",
-			"com/test/publish1/app-gui/0.1.4/app-gui-0.1.4-darwin-amd64.zip.md5",
-			"com/test/publish1/app-gui/0.1.4/app-gui-0.1.4-darwin-amd64.zip.sha1",
-			"com/test/publish1/app-gui/0.1.4/app-gui-0.1.4.pom",
-			"com/test/publish1/app-gui/0.1.4/app-gui-0.1.4.pom.md5",
-			"com/test/publish1/app-gui/0.1.4/app-gui-0.1.4.pom.sha1",
-			"com/test/publish1/app-gui/maven-metadata.xml",
-			"com/test/publish1/app-gui/maven-metadata.xml.md5",
-			"com/test/publish1/app-gui/maven-metadata.xml.sha1",
-			"com/test/publish1/app-server/0.1.4/app-server-0.1.4-linux-amd64.readme",
-			"com/test/publish1/app-server/0.1.4/app-server-0.1.4-linux-amd64.readme.md5",
-			"com/test/publish1/app-server/0.1.4/app-server-0.1.4-linux-amd64.readme.sha1",
-			"com/test/publish1/app-server/0.1.4/app-server-0.1.4-linux-amd64.tar.gz",
-			"com/test/publish1/app-server/0.1.4/app-server-0.1.4-linux-amd64.tar.gz.md5",
-			"com/test/publish1/app-server/0.1.4/app-server-0.1.4-linux-amd64.tar.gz.sha1",
-			"com/test/publish1/app-server/0.1.4/app-server-0.1.4.pom",
-			"com/test/publish1/app-server/0.1.4/app-server-0.1.4.pom.md5",
-			"com/test/publish1/app-server/0.1.4/app-server-0.1.4.pom.sha1",
-			"com/test/publish1/app-server/maven-metadata.xml",
-			"com/test/publish1/app-server/maven-metadata.xml.md5",
-			"com/test/publish1/app-server/maven-metadata.xml.sha1",
-		)
+		l.AssertFiles(publish1Files...)
 	})
 }
 
+// publish1Files is the repository content expected after publishing the
+// multiple-matched test data with group com.test.publish1.
+var publish1Files = []string{
+	"com/test/publish1/app-client/0.1.4/app-client-0.1.4-darwin-amd64.zip",
+	"com/test/publish1/app-client/0.1.4/app-client-0.1.4-darwin-amd64.zip.md5",
+	"com/test/publish1/app-client/0.1.4/app-client-0.1.4-darwin-amd64.zip.sha1",
+	"com/test/publish1/app-client/0.1.4/app-client-0.1.4-linux-386.tar.gz",
+	"com/test/publish1/app-client/0.1.4/app-client-0.1.4-linux-386.tar.gz.md5",
+	"com/test/publish1/app-client/0.1.4/app-client-0.1.4-linux-386.tar.gz.sha1",
+	"com/test/publish1/app-client/0.1.4/app-client-0.1.4-linux-amd64.tar.gz",
+	"com/test/publish1/app-client/0.1.4/app-client-0.1.4-linux-amd64.tar.gz.md5",
+	"com/test/publish1/app-client/0.1.4/app-client-0.1.4-linux-amd64.tar.gz.sha1",
+	"com/test/publish1/app-client/0.1.4/app-client-0.1.4-windows-386.zip",
+	"com/test/publish1/app-client/0.1.4/app-client-0.1.4-windows-386.zip.md5",
+	"com/test/publish1/app-client/0.1.4/app-client-0.1.4-windows-386.zip.sha1",
+	"com/test/publish1/app-client/0.1.4/app-client-0.1.4-windows-amd64.zip",
+	"com/test/publish1/app-client/0.1.4/app-client-0.1.4-windows-amd64.zip.md5",
+	"com/test/publish1/app-client/0.1.4/app-client-0.1.4-windows-amd64.zip.sha1",
+	"com/test/publish1/app-client/0.1.4/app-client-0.1.4.pom",
+	"com/test/publish1/app-client/0.1.4/app-client-0.1.4.pom.md5",
+	"com/test/publish1/app-client/0.1.4/app-client-0.1.4.pom.sha1",
+	"com/test/publish1/app-client/maven-metadata.xml",
+	"com/test/publish1/app-client/maven-metadata.xml.md5",
+	"com/test/publish1/app-client/maven-metadata.xml.sha1",
+	"com/test/publish1/app-gui/0.1.4/app-gui-0.1.4-darwin-amd64.zip",
+	"com/test/publish1/app-gui/0.1.4/app-gui-0.1.4-darwin-amd64.zip.md5",
+	"com/test/publish1/app-gui/0.1.4/app-gui-0.1.4-darwin-amd64.zip.sha1",
+	"com/test/publish1/app-gui/0.1.4/app-gui-0.1.4.pom",
+	"com/test/publish1/app-gui/0.1.4/app-gui-0.1.4.pom.md5",
+	"com/test/publish1/app-gui/0.1.4/app-gui-0.1.4.pom.sha1",
+	"com/test/publish1/app-gui/maven-metadata.xml",
+	"com/test/publish1/app-gui/maven-metadata.xml.md5",
+	"com/test/publish1/app-gui/maven-metadata.xml.sha1",
+	"com/test/publish1/app-server/0.1.4/app-server-0.1.4-linux-amd64.readme",
+	"com/test/publish1/app-server/0.1.4/app-server-0.1.4-linux-amd64.readme.md5",
+	"com/test/publish1/app-server/0.1.4/app-server-0.1.4-linux-amd64.readme.sha1",
+	"com/test/publish1/app-server/0.1.4/app-server-0.1.4-linux-amd64.tar.gz",
+	"com/test/publish1/app-server/0.1.4/app-server-0.1.4-linux-amd64.tar.gz.md5",
+	"com/test/publish1/app-server/0.1.4/app-server-0.1.4-linux-amd64.tar.gz.sha1",
+	"com/test/publish1/app-server/0.1.4/app-server-0.1.4.pom",
+	"com/test/publish1/app-server/0.1.4/app-server-0.1.4.pom.md5",
+	"com/test/publish1/app-server/0.1.4/app-server-0.1.4.pom.sha1",
+	"com/test/publish1/app-server/maven-metadata.xml",
+	"com/test/publish1/app-server/maven-metadata.xml.md5",
+	"com/test/publish1/app-server/maven-metadata.xml.sha1",
+}
+
 func TestPublish5(t *testing.T) {
 	l := LocalTest{
 		t,
@@ -133,7 +137,7 @@ func TestPublish5(t *testing.T) {
 			},
 			GPG: GPG{},
 			Args: Args{
-				Source: "multiple-matched/app*.zip",
+				Source: Patterns{"multiple-matched/app*.zip"},
 				Regexp: "(?P<artifact>app-[^/-]*)-(?P<classifier>[^-]*-[^-]*)-(?P<version>.*)$",
 			}}}
 
@@ -189,7 +193,7 @@ func TestPublish2(t *testing.T) {
 			},
 			GPG: GPG{},
 			Args: Args{
-				Source: "single/release.zip",
+				Source: Patterns{"single/release.zip"},
 			},
 		}}
 
@@ -228,7 +232,7 @@ func TestPublish3(t *testing.T) {
 			},
 			GPG: GPG{},
 			Args: Args{
-				Source: "single-matched/*.zip",
+				Source: Patterns{"single-matched/*.zip"},
 				Regexp: "(?P<artifact>[^/-]*)-(?P<classifier>[^-]*-[^-]*).zip$",
 			}}}
 
@@ -271,7 +275,7 @@ func TestGPGSign1(t *testing.T) {
 				Passphrase: `test`,
 			},
 			Args: Args{
-				Source: "single/release.zip",
+				Source: Patterns{"single/release.zip"},
 				Debug:  true,
 			},
 		}}
@@ -320,7 +324,7 @@ func TestGPGSignInvalidPassphrase(t *testing.T) {
 				Passphrase: `WRONG`,
 			},
 			Args: Args{
-				Source: "single/release.zip",
+				Source: Patterns{"single/release.zip"},
 				Debug:  true,
 			},
 		}}
@@ -349,7 +353,7 @@ func TestGPGSign2(t *testing.T) {
 				Passphrase: `test`,
 			},
 			Args: Args{
-				Source: "multiple-matched/app-client*",
+				Source: Patterns{"multiple-matched/app-client*"},
 				Regexp: "(?P<artifact>app-[^/-]*)-(?P<classifier>[^-]*-[^-]*)-(?P<version>.*).(?P<extension>tar.gz|zip|readme)$",
 			}}}
 
@@ -402,6 +406,39 @@ func TestGPGSign2(t *testing.T) {
 	})
 }
 
+func TestRepositoryForVersion(t *testing.T) {
+	for _, v := range []struct {
+		repo     Repository
+		version  string
+		expected string // empty if an error is expected
+	}{
+		{Repository{URL: "u"}, "1.0", "u"},
+		{Repository{URL: "u"}, "1.0-SNAPSHOT", "u"},
+		{Repository{URL: "u", SnapshotURL: "s"}, "1.0", "u"},
+		{Repository{URL: "u", SnapshotURL: "s"}, "1.0-SNAPSHOT", "s"},
+		{Repository{SnapshotURL: "s", ReleaseURL: "r"}, "1.0", "r"},
+		{Repository{SnapshotURL: "s", ReleaseURL: "r"}, "1.0-SNAPSHOT", "s"},
+		{Repository{ReleaseURL: "r"}, "1.0-SNAPSHOT", ""},
+		{Repository{SnapshotURL: "s"}, "1.0", ""},
+		{Repository{}, "1.0", ""},
+	} {
+		repo, err := v.repo.forVersion(v.version)
+		if v.expected == "" {
+			if err == nil {
+				t.Errorf("expected error for %s in %+v, got %s", v.version, v.repo, repo.URL)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("unexpected error for %s in %+v: %v", v.version, v.repo, err)
+			continue
+		}
+		if repo.URL != v.expected {
+			t.Errorf("expected %s for %s in %+v, got %s", v.expected, v.version, v.repo, repo.URL)
+		}
+	}
+}
+
 // LocalTest .
 type LocalTest struct {
 	*testing.T
@@ -448,6 +485,19 @@ func (l *LocalTest) AssertNoFiles() {
 	}
 }
 
+// AssertFileContent fails the test if the file at path in the local maven
+// resulting repo doesnt have the expected content.
+func (l *LocalTest) AssertFileContent(path, expected string) {
+	basepath := strings.TrimPrefix(l.Maven.Repository.URL, "file://")
+	data, err := ioutil.ReadFile(filepath.Join(basepath, path))
+	if err != nil {
+		l.T.Fatal(err)
+	}
+	if string(data) != expected {
+		l.T.Fatalf("unexpected content in %s:\n\n%s\n\nexpected:\n\n%s\n\n", path, data, expected)
+	}
+}
+
 func (l *LocalTest) expectFiles(path ...string) (bool, []string) {
 	basepath := strings.TrimPrefix(l.Maven.Repository.URL, "file://")
 
@@ -0,0 +1,214 @@
+package mavendeploy
+
+import (
+	"encoding/xml"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Metadata is the root of a maven-metadata.xml file.
+//
+// The artifact level metadata (group/artifact/maven-metadata.xml) lists all
+// versions of an artifact while the version level metadata of snapshots
+// (group/artifact/version/maven-metadata.xml) lists the latest timestamped
+// files of a snapshot version.
+type Metadata struct {
+	XMLName    xml.Name   `xml:"metadata"`
+	GroupID    string     `xml:"groupId"`
+	ArtifactID string     `xml:"artifactId"`
+	Version    string     `xml:"version,omitempty"`
+	Versioning Versioning `xml:"versioning"`
+}
+
+// Versioning holds the versions section of maven-metadata.xml.
+type Versioning struct {
+	Latest           string            `xml:"latest,omitempty"`
+	Release          string            `xml:"release,omitempty"`
+	Snapshot         *Snapshot         `xml:"snapshot,omitempty"`
+	Versions         []string          `xml:"versions>version"`
+	LastUpdated      string            `xml:"lastUpdated,omitempty"`
+	SnapshotVersions []SnapshotVersion `xml:"snapshotVersions>snapshotVersion"`
+}
+
+// MarshalXML implements xml.Marshaler to leave out the versions and
+// snapshotVersions elements when they are empty.
+func (v Versioning) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type versions struct {
+		Version []string `xml:"version"`
+	}
+	type snapshotVersions struct {
+		SnapshotVersion []SnapshotVersion `xml:"snapshotVersion"`
+	}
+	out := struct {
+		Latest           string            `xml:"latest,omitempty"`
+		Release          string            `xml:"release,omitempty"`
+		Snapshot         *Snapshot         `xml:"snapshot,omitempty"`
+		Versions         *versions         `xml:"versions,omitempty"`
+		LastUpdated      string            `xml:"lastUpdated,omitempty"`
+		SnapshotVersions *snapshotVersions `xml:"snapshotVersions,omitempty"`
+	}{
+		Latest:      v.Latest,
+		Release:     v.Release,
+		Snapshot:    v.Snapshot,
+		LastUpdated: v.LastUpdated,
+	}
+	if len(v.Versions) > 0 {
+		out.Versions = &versions{v.Versions}
+	}
+	if len(v.SnapshotVersions) > 0 {
+		out.SnapshotVersions = &snapshotVersions{v.SnapshotVersions}
+	}
+	return e.EncodeElement(out, start)
+}
+
+// Snapshot is the latest deployment of a snapshot version.
+type Snapshot struct {
+	Timestamp   string `xml:"timestamp"`
+	BuildNumber int    `xml:"buildNumber"`
+}
+
+// SnapshotVersion is the latest timestamped version of a file in a snapshot
+// version.
+type SnapshotVersion struct {
+	Classifier string `xml:"classifier,omitempty"`
+	Extension  string `xml:"extension"`
+	Value      string `xml:"value"`
+	Updated    string `xml:"updated"`
+}
+
+// Time formats used in maven-metadata.xml.
+const (
+	metadataTimestamp = "20060102150405"  // lastUpdated and updated
+	snapshotTimestamp = "20060102.150405" // snapshot timestamp
+)
+
+// parseMetadata parses a maven-metadata.xml document.
+func parseMetadata(data []byte) (*Metadata, error) {
+	var m Metadata
+	err := xml.Unmarshal(data, &m)
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// fetchMetadata downloads the artifact level metadata for a, an empty
+// metadata is returned if the artifact has not been published before.
+func fetchMetadata(t transport, a Artifact) (*Metadata, error) {
+	return fetchMetadataFile(t, path.Join(a.artifactDir(), metadataName))
+}
+
+// fetchSnapshotMetadata downloads the version level metadata for a, an empty
+// metadata is returned if the snapshot version has not been published before.
+func fetchSnapshotMetadata(t transport, a Artifact) (*Metadata, error) {
+	return fetchMetadataFile(t, path.Join(a.versionDir(), metadataName))
+}
+
+func fetchMetadataFile(t transport, p string) (*Metadata, error) {
+	data, err := t.Get(p)
+	if err == errNotFound {
+		return &Metadata{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	m, err := parseMetadata(data)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse %s: %v", t.URL(p), err)
+	}
+	return m, nil
+}
+
+// AddVersion merges an artifact version into the metadata. The versions are
+// kept in maven version order and latest and release are updated to the
+// highest known version and highest known non snapshot version.
+func (m *Metadata) AddVersion(a Artifact, now time.Time) {
+	m.GroupID = a.GroupID
+	m.ArtifactID = a.ArtifactID
+	v := &m.Versioning
+	found := false
+	for _, version := range v.Versions {
+		if version == a.Version {
+			found = true
+			break
+		}
+	}
+	if !found {
+		v.Versions = append(v.Versions, a.Version)
+	}
+	sort.SliceStable(v.Versions, func(i, j int) bool {
+		return compareVersions(v.Versions[i], v.Versions[j]) < 0
+	})
+	v.Latest = ""
+	v.Release = ""
+	for _, version := range v.Versions {
+		v.Latest = version
+		if !isSnapshot(version) {
+			v.Release = version
+		}
+	}
+	v.LastUpdated = now.UTC().Format(metadataTimestamp)
+}
+
+// Marshal returns the xml document.
+func (m *Metadata) Marshal() ([]byte, error) {
+	return marshalXML(m)
+}
+
+// marshalXML returns v as an indented xml document with an xml header.
+func marshalXML(v interface{}) ([]byte, error) {
+	output, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(output, '\n')...), nil
+}
+
+// NextSnapshot starts a new snapshot deployment of a and returns the unique
+// timestamped version which replaces SNAPSHOT in the deployed file names,
+// e.g. 1.2.3-SNAPSHOT becomes 1.2.3-20151031.123000-7. The build number is
+// incremented from the previous deployment.
+func (m *Metadata) NextSnapshot(a Artifact, now time.Time) string {
+	m.GroupID = a.GroupID
+	m.ArtifactID = a.ArtifactID
+	m.Version = a.Version
+	buildNumber := 1
+	if m.Versioning.Snapshot != nil {
+		buildNumber = m.Versioning.Snapshot.BuildNumber + 1
+	}
+	m.Versioning.Snapshot = &Snapshot{
+		Timestamp:   now.UTC().Format(snapshotTimestamp),
+		BuildNumber: buildNumber,
+	}
+	m.Versioning.LastUpdated = now.UTC().Format(metadataTimestamp)
+	return fmt.Sprintf("%s%s-%d",
+		strings.TrimSuffix(a.Version, "SNAPSHOT"),
+		m.Versioning.Snapshot.Timestamp, buildNumber)
+}
+
+// AddSnapshotVersion records the timestamped version of a file in the
+// snapshot metadata, replacing any previous entry with the same classifier
+// and extension.
+func (m *Metadata) AddSnapshotVersion(classifier, extension, value string, now time.Time) {
+	sv := SnapshotVersion{
+		Classifier: classifier,
+		Extension:  extension,
+		Value:      value,
+		Updated:    now.UTC().Format(metadataTimestamp),
+	}
+	for i, v := range m.Versioning.SnapshotVersions {
+		if v.Classifier == classifier && v.Extension == extension {
+			m.Versioning.SnapshotVersions[i] = sv
+			return
+		}
+	}
+	m.Versioning.SnapshotVersions = append(m.Versioning.SnapshotVersions, sv)
+}
+
+// isSnapshot returns true if version is a maven snapshot version.
+func isSnapshot(version string) bool {
+	return strings.HasSuffix(version, "SNAPSHOT")
+}
@@ -0,0 +1,179 @@
+package mavendeploy
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMetadataAddVersion(t *testing.T) {
+	now := time.Date(2015, 10, 31, 12, 30, 0, 0, time.UTC)
+	m, err := parseMetadata([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<metadata>
+  <groupId>com.test</groupId>
+  <artifactId>app-client</artifactId>
+  <versioning>
+    <latest>0.1.10</latest>
+    <release>0.1.10</release>
+    <versions>
+      <version>0.1.3</version>
+      <version>0.1.10</version>
+      <version>0.1.2-SNAPSHOT</version>
+    </versions>
+    <lastUpdated>20151001000000</lastUpdated>
+  </versioning>
+</metadata>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m.AddVersion(Artifact{GroupID: "com.test", ArtifactID: "app-client", Version: "0.1.4"}, now)
+	m.AddVersion(Artifact{GroupID: "com.test", ArtifactID: "app-client", Version: "0.1.4"}, now)
+	expected := Versioning{
+		Latest:      "0.1.10",
+		Release:     "0.1.10",
+		Versions:    []string{"0.1.2-SNAPSHOT", "0.1.3", "0.1.4", "0.1.10"},
+		LastUpdated: "20151031123000",
+	}
+	if !reflect.DeepEqual(m.Versioning, expected) {
+		t.Fatalf("unexpected versioning %+v", m.Versioning)
+	}
+
+	m.AddVersion(Artifact{GroupID: "com.test", ArtifactID: "app-client", Version: "0.2.0-SNAPSHOT"}, now)
+	if m.Versioning.Latest != "0.2.0-SNAPSHOT" || m.Versioning.Release != "0.1.10" {
+		t.Fatalf("unexpected latest/release %+v", m.Versioning)
+	}
+}
+
+func TestNativeMetadataMerge(t *testing.T) {
+	l := LocalTest{
+		t,
+		&Maven{
+			Repository: Repository{
+				Username: "u",
+				Password: "p",
+			},
+			Artifact: Artifact{
+				GroupID:    "com.test.merge",
+				ArtifactID: "release",
+				Extension:  "zip",
+			},
+			Args: Args{
+				Source:  Patterns{"single/release.zip"},
+				Backend: BackendNative,
+			},
+		}}
+
+	l.Run(func(m *Maven) {
+		for _, version := range []string{"1.10.0", "1.9.0", "1.10.0"} {
+			m.Artifact.Version = version
+			err := m.Publish()
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		t, err := newTransport(m.Repository, m.httpClient())
+		if err != nil {
+			l.Fatal(err)
+		}
+		metadata, err := fetchMetadata(t, m.Artifact)
+		if err != nil {
+			l.Fatal(err)
+		}
+		v := metadata.Versioning
+		if !reflect.DeepEqual(v.Versions, []string{"1.9.0", "1.10.0"}) ||
+			v.Latest != "1.10.0" || v.Release != "1.10.0" {
+			l.Fatalf("unexpected versioning %+v", v)
+		}
+	})
+}
+
+func TestNativeSnapshot(t *testing.T) {
+	l := LocalTest{
+		t,
+		&Maven{
+			Repository: Repository{
+				Username: "u",
+				Password: "p",
+			},
+			Artifact: Artifact{
+				GroupID: "com.test.snapshot",
+				Version: "0.1.4-SNAPSHOT",
+			},
+			Args: Args{
+				Source:  Patterns{"multiple-matched/app-client-*-386*"},
+				Regexp:  `(?P<artifact>app-client)-(?P<classifier>[^-]*-[^-]*)-.*\.(?P<extension>tar\.gz|zip)$`,
+				Backend: BackendNative,
+			},
+		}}
+
+	l.Run(func(m *Maven) {
+		for _, ts := range []string{"2015-10-31T12:00:00Z", "2015-11-01T08:30:15Z"} {
+			now, err := time.Parse(time.RFC3339, ts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			m.clock = func() time.Time { return now }
+			err = m.Publish()
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		var files []string
+		for _, v := range []string{
+			"app-client-0.1.4-20151031.120000-1-linux-386.tar.gz",
+			"app-client-0.1.4-20151031.120000-1-windows-386.zip",
+			"app-client-0.1.4-20151031.120000-1.pom",
+			"app-client-0.1.4-20151101.083015-2-linux-386.tar.gz",
+			"app-client-0.1.4-20151101.083015-2-windows-386.zip",
+			"app-client-0.1.4-20151101.083015-2.pom",
+			"maven-metadata.xml",
+		} {
+			p := "com/test/snapshot/app-client/0.1.4-SNAPSHOT/" + v
+			files = append(files, p, p+".md5", p+".sha1")
+		}
+		p := "com/test/snapshot/app-client/maven-metadata.xml"
+		files = append(files, p, p+".md5", p+".sha1")
+		l.AssertFiles(files...)
+
+		t, err := newTransport(m.Repository, m.httpClient())
+		if err != nil {
+			l.Fatal(err)
+		}
+		a := Artifact{GroupID: "com.test.snapshot", ArtifactID: "app-client", Version: "0.1.4-SNAPSHOT"}
+		snapshot, err := fetchSnapshotMetadata(t, a)
+		if err != nil {
+			l.Fatal(err)
+		}
+		expected := Metadata{
+			XMLName:    snapshot.XMLName,
+			GroupID:    "com.test.snapshot",
+			ArtifactID: "app-client",
+			Version:    "0.1.4-SNAPSHOT",
+			Versioning: Versioning{
+				Snapshot: &Snapshot{
+					Timestamp:   "20151101.083015",
+					BuildNumber: 2,
+				},
+				LastUpdated: "20151101083015",
+				SnapshotVersions: []SnapshotVersion{
+					{"linux-386", "tar.gz", "0.1.4-20151101.083015-2", "20151101083015"},
+					{"windows-386", "zip", "0.1.4-20151101.083015-2", "20151101083015"},
+					{"", "pom", "0.1.4-20151101.083015-2", "20151101083015"},
+				},
+			},
+		}
+		if !reflect.DeepEqual(*snapshot, expected) {
+			l.Fatalf("unexpected snapshot metadata %+v", snapshot)
+		}
+		metadata, err := fetchMetadata(t, a)
+		if err != nil {
+			l.Fatal(err)
+		}
+		v := metadata.Versioning
+		if !reflect.DeepEqual(v.Versions, []string{"0.1.4-SNAPSHOT"}) ||
+			v.Latest != "0.1.4-SNAPSHOT" || v.Release != "" {
+			l.Fatalf("unexpected versioning %+v", v)
+		}
+	})
+}
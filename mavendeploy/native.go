@@ -0,0 +1,383 @@
+package mavendeploy
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// publishNative deploys the prepared artifacts by writing the maven
+// repository layout directly instead of invoking mvn.
+func (mvn *Maven) publishNative() error {
+	return mvn.forEachGroup(func(g *Maven, key string) error {
+		t, err := g.transport(g.artifacts[key][0])
+		if err != nil {
+			return err
+		}
+		skip, err := g.checkExisting(t, key)
+		if err != nil || skip {
+			return err
+		}
+		return g.retry(key, func() error {
+			return g.deploy(t, g.artifacts[key])
+		})
+	})
+}
+
+// repository returns the repository version is deployed to, which for
+// releases is the staging repository if staging is enabled and Maven Central
+// for the central backend.
+func (mvn *Maven) repository(version string) (Repository, error) {
+	if mvn.Args.Backend == BackendCentral {
+		if isSnapshot(version) {
+			return Repository{}, fmt.Errorf("snapshot version %s can't be published to maven central", version)
+		}
+		return Repository{URL: centralRepositoryURL}, nil
+	}
+	if !mvn.staged(version) {
+		return mvn.Repository.forVersion(version)
+	}
+	id := stagingPlaceholder
+	if mvn.deployment != nil && mvn.deployment.stagingRepository != "" {
+		id = mvn.deployment.stagingRepository
+	}
+	repo := mvn.Repository
+	repo.URL = mvn.Staging.deployURL(id)
+	return repo, nil
+}
+
+// transport returns the transport of the repository a is deployed to.
+func (mvn *Maven) transport(a Artifact) (transport, error) {
+	repo, err := mvn.repository(a.Version)
+	if err != nil {
+		return nil, err
+	}
+	return newTransport(repo, mvn.httpClient())
+}
+
+// deploy uploads a group of artifacts sharing the same
+// groupID:artifactID:version followed by the pom and the artifact metadata.
+//
+// Snapshot versions are deployed as unique timestamped versions along with
+// version level metadata.
+func (mvn *Maven) deploy(t transport, artifacts []Artifact) error {
+	a := artifacts[0]
+	if a.GroupID == "" || a.ArtifactID == "" || a.Version == "" {
+		return fmt.Errorf("group, artifact and version are %s: %s", errRequiredValue, a.key())
+	}
+	now := mvn.now()
+	var snapshot *Metadata
+	if isSnapshot(a.Version) {
+		var err error
+		snapshot, err = fetchSnapshotMetadata(t, a)
+		if err != nil {
+			return err
+		}
+		unique := snapshot.NextSnapshot(a, now)
+		mvn.deployment.setUniqueVersion(a.key(), unique)
+		a.uniqueVersion = unique
+		artifacts = append([]Artifact(nil), artifacts...)
+		for i := range artifacts {
+			artifacts[i].uniqueVersion = unique
+		}
+	}
+	dir := a.versionDir()
+	for _, v := range artifacts {
+		err := mvn.putFile(t, path.Join(dir, v.fileName()), v.file, true)
+		if err != nil {
+			return err
+		}
+		if snapshot != nil {
+			snapshot.AddSnapshotVersion(v.Classifier, v.extension(), v.uniqueVersion, now)
+		}
+	}
+	err := mvn.putPom(t, a)
+	if err != nil {
+		return err
+	}
+	if snapshot != nil {
+		snapshot.AddSnapshotVersion("", "pom", a.uniqueVersion, now)
+		data, err := snapshot.Marshal()
+		if err != nil {
+			return err
+		}
+		err = mvn.putData(t, path.Join(dir, metadataName), "(generated metadata)", data, false)
+		if err != nil {
+			return err
+		}
+	}
+	return mvn.updateMetadata(t, a, now)
+}
+
+// putPom uploads the pom file for a, or a generated pom if no pom file is
+// used.
+func (mvn *Maven) putPom(t transport, a Artifact) error {
+	p := path.Join(a.versionDir(), a.pomName())
+	filename, pom, err := mvn.pomSource(a.key())
+	if err != nil {
+		return err
+	}
+	if filename != "" {
+		return mvn.putFile(t, p, filename, true)
+	}
+	return mvn.putData(t, p, "(generated pom)", pom, true)
+}
+
+// updateMetadata merges the artifact version into the remote artifact level
+// maven-metadata.xml. The staging repository of a staged release is empty so
+// the metadata is fetched from the release repository it is promoted to.
+func (mvn *Maven) updateMetadata(t transport, a Artifact, now time.Time) error {
+	unlock := mvn.deployment.lock(t.URL(a.artifactDir()))
+	defer unlock()
+	src := t
+	if mvn.staged(a.Version) && !mvn.Args.DryRun {
+		if mvn.Repository.URL == "" && mvn.Repository.ReleaseURL == "" {
+			mvn.infof("warning: no release repository is configured, the staged %s of %s only lists %s",
+				metadataName, a.key(), a.Version)
+		} else {
+			var err error
+			src, err = mvn.releaseTransport(a.Version)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	m, err := fetchMetadata(src, a)
+	if err != nil {
+		return err
+	}
+	m.AddVersion(a, now)
+	data, err := m.Marshal()
+	if err != nil {
+		return err
+	}
+	return mvn.putData(t, path.Join(a.artifactDir(), metadataName), "(generated metadata)", data, false)
+}
+
+// now returns the current time, or the time of the test clock if set.
+func (mvn *Maven) now() time.Time {
+	if mvn.clock != nil {
+		return mvn.clock()
+	}
+	return time.Now()
+}
+
+// putFile uploads a local file along with its checksums and, if signed is
+// true and signing is enabled, its signature.
+func (mvn *Maven) putFile(t transport, p, filename string, signed bool) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	return mvn.put(t, p, filename, f, fi.Size(), signed)
+}
+
+// putData uploads data along with its checksums and, if signed is true and
+// signing is enabled, its signature. The source describes the origin of the
+// data.
+func (mvn *Maven) putData(t transport, p, source string, data []byte, signed bool) error {
+	return mvn.put(t, p, source, bytes.NewReader(data), int64(len(data)), signed)
+}
+
+// put uploads r to p while hashing and optionally signing the content and
+// then uploads a checksum file for each hash followed by the signature. Each
+// upload is recorded in the deployment.
+func (mvn *Maven) put(t transport, p, source string, r io.Reader, size int64, signed bool) error {
+	exts := mvn.checksums()
+	hashes, w, err := newHashes(exts)
+	if err != nil {
+		return err
+	}
+	var sw *signatureWriter
+	if signed && mvn.signer != nil {
+		sw = mvn.signer.newSignatureWriter()
+		w = io.MultiWriter(w, sw)
+	}
+	if !mvn.Args.DryRun {
+		mvn.infof("PUT %s", t.URL(p))
+	}
+	pt, ok := t.(propertiesTransport)
+	// properties are attached to the artifacts, poms and signatures
+	if ok && mvn.properties != nil && (signed || strings.HasSuffix(p, ".asc")) {
+		err = pt.PutWithProperties(p, io.TeeReader(r, w), size, mvn.matrixParams())
+	} else {
+		err = t.Put(p, io.TeeReader(r, w), size)
+	}
+	var signature []byte
+	if sw != nil {
+		var serr error
+		signature, serr = sw.Signature()
+		if err == nil {
+			err = serr
+		}
+	}
+	if err != nil {
+		return err
+	}
+	uploads := []upload{{
+		Source:    source,
+		URL:       t.URL(p),
+		Size:      size,
+		Checksums: make(map[string]string, len(exts)),
+	}}
+	for i, h := range hashes {
+		sum := []byte(hex.EncodeToString(h.Sum(nil)))
+		uploads[0].Checksums[exts[i]] = string(sum)
+		uploads = append(uploads, upload{
+			Source: "(" + exts[i] + ")",
+			URL:    t.URL(p + "." + exts[i]),
+			Size:   int64(len(sum)),
+		})
+		err := t.Put(p+"."+exts[i], bytes.NewReader(sum), int64(len(sum)))
+		if err != nil {
+			return err
+		}
+	}
+	mvn.deployment.record(uploads...)
+	if signature != nil {
+		return mvn.putData(t, p+".asc", "(signature)", signature, false)
+	}
+	return nil
+}
+
+// checksumAlgorithms are the supported checksum file extensions.
+var checksumAlgorithms = map[string]func() hash.Hash{
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+// newHashes returns a hash for each of the checksum algorithms exts and a
+// writer which writes to all of them.
+func newHashes(exts []string) ([]hash.Hash, io.Writer, error) {
+	var hashes []hash.Hash
+	var writers []io.Writer
+	for _, ext := range exts {
+		newHash, ok := checksumAlgorithms[ext]
+		if !ok {
+			return nil, nil, fmt.Errorf("checksum %s is %s", ext, errInvalidValue)
+		}
+		h := newHash()
+		hashes = append(hashes, h)
+		writers = append(writers, h)
+	}
+	return hashes, io.MultiWriter(writers...), nil
+}
+
+// defaultChecksums are the checksums written by maven-deploy-plugin.
+var defaultChecksums = []string{"md5", "sha1"}
+
+// checksums returns the configured checksum algorithms.
+func (mvn *Maven) checksums() []string {
+	if len(mvn.Args.Checksums) == 0 {
+		return defaultChecksums
+	}
+	return mvn.Args.Checksums
+}
+
+// validateChecksums verifies that all configured checksum algorithms are
+// supported by the backend.
+func (mvn *Maven) validateChecksums() error {
+	seen := make(map[string]bool)
+	for _, v := range mvn.Args.Checksums {
+		if _, ok := checksumAlgorithms[v]; !ok {
+			return fmt.Errorf("checksum %s is %s", v, errInvalidValue)
+		}
+		if seen[v] {
+			return fmt.Errorf("checksum %s is listed more than once", v)
+		}
+		seen[v] = true
+	}
+	if mvn.Args.Backend == BackendNative || mvn.Args.Backend == BackendCentral || len(mvn.Args.Checksums) == 0 {
+		return nil
+	}
+	if strings.Join(mvn.Args.Checksums, ",") != strings.Join(defaultChecksums, ",") {
+		return fmt.Errorf("checksums %v are only supported by the %s and %s backends, mvn writes %v",
+			mvn.Args.Checksums, BackendNative, BackendCentral, defaultChecksums)
+	}
+	return nil
+}
+
+// artifactKeys returns the keys of the prepared artifacts in sorted order.
+func (mvn *Maven) artifactKeys() []string {
+	var keys []string
+	for k := range mvn.artifacts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// metadataName is the file name of the maven repository metadata.
+const metadataName = "maven-metadata.xml"
+
+// key returns the groupID:artifactID:version identifier of the artifact.
+func (a Artifact) key() string {
+	return fmt.Sprintf("%s:%s:%s", a.GroupID, a.ArtifactID, a.Version)
+}
+
+// extension returns the artifact extension, falling back to the extension
+// of the source file like maven-deploy-plugin does.
+func (a Artifact) extension() string {
+	if a.Extension != "" {
+		return a.Extension
+	}
+	return strings.TrimPrefix(filepath.Ext(a.file), ".")
+}
+
+// artifactDir returns the repository path of the artifact, e.g.
+// org/springframework/spring-core.
+func (a Artifact) artifactDir() string {
+	return path.Join(strings.Replace(a.GroupID, ".", "/", -1), a.ArtifactID)
+}
+
+// versionDir returns the repository path of the artifact version, e.g.
+// org/springframework/spring-core/4.1.3.RELEASE.
+func (a Artifact) versionDir() string {
+	return path.Join(a.artifactDir(), a.Version)
+}
+
+// baseName returns the file name of the artifact without classifier and
+// extension. Snapshots use the unique timestamped version once it is known.
+func (a Artifact) baseName() string {
+	if a.uniqueVersion != "" {
+		return a.ArtifactID + "-" + a.uniqueVersion
+	}
+	return a.ArtifactID + "-" + a.Version
+}
+
+// fileName returns the repository file name of the artifact, e.g.
+// spring-core-4.1.3.RELEASE-sources.jar.
+func (a Artifact) fileName() string {
+	name := a.baseName()
+	if a.Classifier != "" {
+		name += "-" + a.Classifier
+	}
+	if ext := a.extension(); ext != "" {
+		name += "." + ext
+	}
+	return name
+}
+
+// pomName returns the repository file name of the artifact pom.
+func (a Artifact) pomName() string {
+	return a.baseName() + ".pom"
+}
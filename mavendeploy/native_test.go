@@ -0,0 +1,368 @@
+package mavendeploy
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestNativePublish1(t *testing.T) {
+	l := LocalTest{
+		t,
+		&Maven{
+			Repository: Repository{
+				Username: "u",
+				Password: "p",
+			},
+			Artifact: Artifact{
+				GroupID: "com.test.publish1",
+			},
+			GPG: GPG{},
+			Args: Args{
+				Source:  Patterns{"multiple-matched/app*"},
+				Regexp:  "(?P<artifact>app-[^/-]*)-(?P<classifier>[^-]*-[^-]*)-(?P<version>.*).(?P<extension>tar.gz|zip|readme)$",
+				Backend: BackendNative,
+			}}}
+
+	l.Run(func(m *Maven) {
+		err := m.Publish()
+		if err != nil {
+			t.Fatal(err)
+		}
+		l.AssertFiles(publish1Files...)
+	})
+}
+
+func TestNativePublish2(t *testing.T) {
+	l := LocalTest{
+		t,
+		&Maven{
+			Repository: Repository{
+				Username: "u",
+				Password: "p",
+			},
+			Artifact: Artifact{
+				GroupID:    "com.test.publish2",
+				ArtifactID: "release",
+				Extension:  "zip",
+				Version:    "1.2.3",
+			},
+			GPG: GPG{},
+			Args: Args{
+				Source:  Patterns{"single/release.zip"},
+				Backend: BackendNative,
+			},
+		}}
+
+	l.Run(func(m *Maven) {
+		err := m.Publish()
+		if err != nil {
+			t.Fatal(err)
+		}
+		l.AssertFiles(
+			"com/test/publish2/release/1.2.3/release-1.2.3.pom",
+			"com/test/publish2/release/1.2.3/release-1.2.3.pom.md5",
+			"com/test/publish2/release/1.2.3/release-1.2.3.pom.sha1",
+			"com/test/publish2/release/1.2.3/release-1.2.3.zip",
+			"com/test/publish2/release/1.2.3/release-1.2.3.zip.md5",
+			"com/test/publish2/release/1.2.3/release-1.2.3.zip.sha1",
+			"com/test/publish2/release/maven-metadata.xml",
+			"com/test/publish2/release/maven-metadata.xml.md5",
+			"com/test/publish2/release/maven-metadata.xml.sha1",
+		)
+		l.AssertFileContent(
+			"com/test/publish2/release/1.2.3/release-1.2.3.zip.sha1",
+			"3f786850e387550fdab836ed7e6dc881de23001b")
+	})
+}
+
+func TestNativeChecksums(t *testing.T) {
+	l := LocalTest{
+		t,
+		&Maven{
+			Repository: Repository{
+				Username: "u",
+				Password: "p",
+			},
+			Artifact: Artifact{
+				GroupID:    "com.test.checksums",
+				ArtifactID: "release",
+				Extension:  "zip",
+				Version:    "1.2.3",
+			},
+			GPG: GPG{
+				PrivateKey: privateKey,
+				Passphrase: `test`,
+			},
+			Args: Args{
+				Source:    Patterns{"single/release.zip"},
+				Backend:   BackendNative,
+				Checksums: []string{"sha256", "sha512"},
+			},
+		}}
+
+	l.Run(func(m *Maven) {
+		err := m.Publish()
+		if err != nil {
+			t.Fatal(err)
+		}
+		l.AssertFiles(
+			"com/test/checksums/release/1.2.3/release-1.2.3.pom",
+			"com/test/checksums/release/1.2.3/release-1.2.3.pom.asc",
+			"com/test/checksums/release/1.2.3/release-1.2.3.pom.asc.sha256",
+			"com/test/checksums/release/1.2.3/release-1.2.3.pom.asc.sha512",
+			"com/test/checksums/release/1.2.3/release-1.2.3.pom.sha256",
+			"com/test/checksums/release/1.2.3/release-1.2.3.pom.sha512",
+			"com/test/checksums/release/1.2.3/release-1.2.3.zip",
+			"com/test/checksums/release/1.2.3/release-1.2.3.zip.asc",
+			"com/test/checksums/release/1.2.3/release-1.2.3.zip.asc.sha256",
+			"com/test/checksums/release/1.2.3/release-1.2.3.zip.asc.sha512",
+			"com/test/checksums/release/1.2.3/release-1.2.3.zip.sha256",
+			"com/test/checksums/release/1.2.3/release-1.2.3.zip.sha512",
+			"com/test/checksums/release/maven-metadata.xml",
+			"com/test/checksums/release/maven-metadata.xml.sha256",
+			"com/test/checksums/release/maven-metadata.xml.sha512",
+		)
+		l.AssertFileContent(
+			"com/test/checksums/release/1.2.3/release-1.2.3.zip.sha256",
+			"87428fc522803d31065e7bce3cf03fe475096631e5e07bbd7a0fde60c4cf25c7")
+		l.AssertFileContent(
+			"com/test/checksums/release/1.2.3/release-1.2.3.zip.sha512",
+			"162b0b32f02482d5aca0a7c93dd03ceac3acd7e410a5f18f3fb990fc958ae0df"+
+				"6f32233b91831eaf99ca581a8c4ddf9c8ba315ac482db6d4ea01cc7884a635be")
+	})
+}
+
+func TestChecksumsInvalid(t *testing.T) {
+	for _, v := range []Args{
+		{Backend: BackendNative, Checksums: []string{"sha384"}},
+		{Backend: BackendNative, Checksums: []string{"sha1", "sha1"}},
+		{Backend: BackendMvn, Checksums: []string{"md5", "sha1", "sha256"}},
+	} {
+		v.Source = Patterns{"single/release.zip"}
+		mvn := &Maven{
+			Repository: Repository{
+				Username: "u",
+				Password: "p",
+				URL:      "file:///nonexistent",
+			},
+			Artifact: Artifact{
+				GroupID:    "com.test.checksums",
+				ArtifactID: "release",
+				Version:    "1.2.3",
+			},
+			Args:          v,
+			workspacePath: "test-data/",
+			quiet:         true,
+		}
+		err := mvn.Publish()
+		if err == nil {
+			t.Fatalf("expected error for %v", v.Checksums)
+		}
+	}
+}
+
+func TestNativeSnapshotAndReleaseURL(t *testing.T) {
+	repo := newFakeRepo()
+	server := httptest.NewServer(repo)
+	defer server.Close()
+
+	mvn := &Maven{
+		Repository: Repository{
+			Username:    "u",
+			Password:    "p",
+			SnapshotURL: server.URL + "/snapshots",
+			ReleaseURL:  server.URL + "/releases",
+		},
+		Artifact: Artifact{
+			GroupID: "com.test.mixed",
+		},
+		Args: Args{
+			Source:  Patterns{"mixed/*"},
+			Regexp:  `(?P<artifact>[a-z]+)-(?P<version>.*)\.(?P<extension>zip)$`,
+			Backend: BackendNative,
+		},
+		workspacePath: "test-data/",
+		quiet:         true,
+	}
+	err := mvn.Publish()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var releases, snapshots int
+	for _, v := range repo.Files() {
+		switch {
+		case strings.HasPrefix(v, "/releases/com/test/mixed/app/"):
+			releases++
+		case strings.HasPrefix(v, "/snapshots/com/test/mixed/lib/"):
+			snapshots++
+		default:
+			t.Fatalf("unexpected file %s", v)
+		}
+	}
+	if releases != 9 || snapshots != 12 {
+		t.Fatalf("unexpected files:\n%s", strings.Join(repo.Files(), "\n"))
+	}
+}
+
+func TestNativeRefuseSnapshotToRelease(t *testing.T) {
+	repo := newFakeRepo()
+	server := httptest.NewServer(repo)
+	defer server.Close()
+
+	mvn := &Maven{
+		Repository: Repository{
+			Username:   "u",
+			Password:   "p",
+			ReleaseURL: server.URL + "/releases",
+		},
+		Artifact: Artifact{
+			GroupID: "com.test.mixed",
+		},
+		Args: Args{
+			Source:  Patterns{"mixed/*"},
+			Regexp:  `(?P<artifact>[a-z]+)-(?P<version>.*)\.(?P<extension>zip)$`,
+			Backend: BackendNative,
+		},
+		workspacePath: "test-data/",
+		quiet:         true,
+	}
+	err := mvn.Publish()
+	if err == nil || !strings.Contains(err.Error(), "refusing to deploy snapshot version 1.1.0-SNAPSHOT") {
+		t.Fatalf("expected refusal, got %v", err)
+	}
+	if files := repo.Files(); len(files) > 0 {
+		t.Fatalf("expected no uploads, got:\n%s", strings.Join(files, "\n"))
+	}
+}
+
+func TestNativePublishHTTP(t *testing.T) {
+	repo := newFakeRepo()
+	server := httptest.NewServer(repo)
+	defer server.Close()
+
+	mvn := &Maven{
+		Repository: Repository{
+			Username: "u",
+			Password: "p",
+			URL:      server.URL + "/repository/releases/",
+		},
+		Artifact: Artifact{
+			GroupID:    "com.test.http",
+			ArtifactID: "release",
+			Extension:  "zip",
+			Version:    "1.2.3",
+		},
+		Args: Args{
+			Source:  Patterns{"single/release.zip"},
+			Backend: BackendNative,
+		},
+		workspacePath: "test-data/",
+		quiet:         true,
+	}
+	err := mvn.Publish()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"/repository/releases/com/test/http/release/1.2.3/release-1.2.3.pom",
+		"/repository/releases/com/test/http/release/1.2.3/release-1.2.3.pom.md5",
+		"/repository/releases/com/test/http/release/1.2.3/release-1.2.3.pom.sha1",
+		"/repository/releases/com/test/http/release/1.2.3/release-1.2.3.zip",
+		"/repository/releases/com/test/http/release/1.2.3/release-1.2.3.zip.md5",
+		"/repository/releases/com/test/http/release/1.2.3/release-1.2.3.zip.sha1",
+		"/repository/releases/com/test/http/release/maven-metadata.xml",
+		"/repository/releases/com/test/http/release/maven-metadata.xml.md5",
+		"/repository/releases/com/test/http/release/maven-metadata.xml.sha1",
+	}
+	if files := repo.Files(); !reflect.DeepEqual(files, expected) {
+		t.Fatalf("unexpected files:\n%s", strings.Join(files, "\n"))
+	}
+}
+
+func TestNativePublishHTTPUnauthorized(t *testing.T) {
+	repo := newFakeRepo()
+	server := httptest.NewServer(repo)
+	defer server.Close()
+
+	mvn := &Maven{
+		Repository: Repository{
+			Username: "u",
+			Password: "wrong",
+			URL:      server.URL,
+		},
+		Artifact: Artifact{
+			GroupID:    "com.test.http",
+			ArtifactID: "release",
+			Version:    "1.2.3",
+		},
+		Args: Args{
+			Source:  Patterns{"single/release.zip"},
+			Backend: BackendNative,
+		},
+		workspacePath: "test-data/",
+		quiet:         true,
+	}
+	err := mvn.Publish()
+	var herr *httpError
+	if !errors.As(err, &herr) || herr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected unauthorized error, got %v", err)
+	}
+}
+
+// fakeRepo is a minimal in memory maven repository server which accepts
+// uploads authenticated with the username u and password p.
+type fakeRepo struct {
+	mu    sync.Mutex
+	files map[string][]byte
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{files: make(map[string][]byte)}
+}
+
+func (f *fakeRepo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch r.Method {
+	case "GET", "HEAD":
+		data, ok := f.files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(data)
+	case "PUT":
+		if u, p, ok := r.BasicAuth(); !ok || u != "u" || p != "p" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		data, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.files[r.URL.Path] = data
+		w.WriteHeader(http.StatusCreated)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// Files returns the sorted paths of all uploaded files.
+func (f *fakeRepo) Files() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var files []string
+	for k := range f.files {
+		files = append(files, k)
+	}
+	sort.Strings(files)
+	return files
+}
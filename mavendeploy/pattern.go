@@ -0,0 +1,113 @@
+package mavendeploy
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// patternDefaults are the sub-patterns of placeholders without alternatives.
+// Placeholders not listed here match a single name part without dashes, e.g.
+// {os} or {arch}. An artifact only has dash separated name parts not starting
+// with a digit after the first one so it never takes parts of a version. A
+// version has any number of dash separated qualifiers, e.g. 1.2.3-1 or
+// 1.0.0-beta-2, but qualifiers of a version without a dot have to start with a
+// letter, e.g. 1-SNAPSHOT, so that a name part like 386 is not a version.
+var patternDefaults = map[string]string{
+	"group":      `[^/]+?`,
+	"artifact":   `[^/-]+(?:-[^/0-9-][^/-]*)*?`,
+	"version":    `[0-9]+(?:(?:\.[0-9A-Za-z]+)+?(?:-[0-9A-Za-z]+(?:\.[0-9A-Za-z]+)*?)*?|(?:-[A-Za-z][0-9A-Za-z]*(?:\.[0-9A-Za-z]+)*?)*?)`,
+	"classifier": `[^/]+?`,
+	"extension":  `(?:tar\.)?[^/.]+`,
+}
+
+// patternGreedy are the placeholders which may match a different number of
+// name parts, see compileGreedyPattern.
+var patternGreedy = map[string]bool{
+	"group":      true,
+	"artifact":   true,
+	"classifier": true,
+}
+
+const patternDefault = `[^/-]+`
+
+var patternName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// compilePattern compiles a file name pattern such as
+// {artifact}-{os}-{arch}-{version}.{extension:tar.gz|zip} into a regexp with
+// a named group for each placeholder.
+//
+// A placeholder is either {name}, matching the default sub-pattern of name,
+// or {name:a|b} matching one of the literal alternatives. A * matches any
+// text within a path element and all other text is matched literally. The
+// pattern is matched against the end of the workspace relative path so it
+// can be a file name or include parent directories.
+func compilePattern(pattern string) (string, error) {
+	return buildPattern(pattern, false)
+}
+
+// compileGreedyPattern compiles pattern like compilePattern but the group,
+// artifact and classifier placeholders match as many name parts as possible
+// instead of as few. A file matched differently by both regexps is
+// ambiguous, e.g. app-client-linux-amd64-1.0.zip for
+// {artifact}-{classifier}-{version}.{extension}.
+func compileGreedyPattern(pattern string) (string, error) {
+	return buildPattern(pattern, true)
+}
+
+func buildPattern(pattern string, greedy bool) (string, error) {
+	var b bytes.Buffer
+	b.WriteString(`(?:^|/)`)
+	seen := make(map[string]bool)
+	for rest := pattern; rest != ""; {
+		i := strings.IndexAny(rest, "{}*")
+		if i < 0 {
+			b.WriteString(regexp.QuoteMeta(rest))
+			break
+		}
+		b.WriteString(regexp.QuoteMeta(rest[:i]))
+		switch rest[i] {
+		case '*':
+			b.WriteString(`[^/]*?`)
+			rest = rest[i+1:]
+			continue
+		case '}':
+			return "", fmt.Errorf("pattern '%s' has an unexpected }", pattern)
+		}
+		end := strings.Index(rest[i:], "}")
+		if end < 0 {
+			return "", fmt.Errorf("pattern '%s' has an unclosed {", pattern)
+		}
+		placeholder := rest[i+1 : i+end]
+		rest = rest[i+end+1:]
+		name, alternatives := placeholder, ""
+		if j := strings.Index(placeholder, ":"); j >= 0 {
+			name, alternatives = placeholder[:j], placeholder[j+1:]
+		}
+		if !patternName.MatchString(name) {
+			return "", fmt.Errorf("pattern '%s' has an invalid placeholder {%s}", pattern, placeholder)
+		}
+		if seen[name] {
+			return "", fmt.Errorf("pattern '%s' has more than one {%s}", pattern, name)
+		}
+		seen[name] = true
+		expr, ok := patternDefaults[name]
+		if !ok {
+			expr = patternDefault
+		}
+		if greedy && patternGreedy[name] {
+			expr = strings.NewReplacer("+?", "+", "*?", "*").Replace(expr)
+		}
+		if alternatives != "" {
+			var quoted []string
+			for _, a := range strings.Split(alternatives, "|") {
+				quoted = append(quoted, regexp.QuoteMeta(a))
+			}
+			expr = strings.Join(quoted, "|")
+		}
+		fmt.Fprintf(&b, "(?P<%s>%s)", name, expr)
+	}
+	b.WriteString("$")
+	return b.String(), nil
+}
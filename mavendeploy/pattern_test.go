@@ -0,0 +1,171 @@
+package mavendeploy
+
+import (
+	"bytes"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestCompilePattern(t *testing.T) {
+	for _, v := range []struct {
+		pattern  string
+		expected string
+		err      string
+	}{
+		{"{artifact}-{version}.{extension}", `(?:^|/)(?P<artifact>[^/-]+(?:-[^/0-9-][^/-]*)*?)-(?P<version>[0-9]+(?:(?:\.[0-9A-Za-z]+)+?(?:-[0-9A-Za-z]+(?:\.[0-9A-Za-z]+)*?)*?|(?:-[A-Za-z][0-9A-Za-z]*(?:\.[0-9A-Za-z]+)*?)*?))\.(?P<extension>(?:tar\.)?[^/.]+)$`, ""},
+		{"dist/*/{os}.{extension:tar.gz|zip}", `(?:^|/)dist/[^/]*?/(?P<os>[^/-]+)\.(?P<extension>tar\.gz|zip)$`, ""},
+		{"{artifact}-{version", "", "unclosed {"},
+		{"{artifact}}", "", "unexpected }"},
+		{"{os-arch}", "", "invalid placeholder {os-arch}"},
+		{"{version}-{version}", "", "more than one {version}"},
+	} {
+		expr, err := compilePattern(v.pattern)
+		if v.err != "" {
+			if err == nil || !strings.Contains(err.Error(), v.err) {
+				t.Fatalf("%s: expected error %q, got %v", v.pattern, v.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expr != v.expected {
+			t.Fatalf("%s: expected %s, got %s", v.pattern, v.expected, expr)
+		}
+	}
+}
+
+func TestPreparePattern(t *testing.T) {
+	var log bytes.Buffer
+	mvn := &Maven{
+		Artifact: Artifact{
+			GroupID:    "com.test.pattern",
+			Classifier: "{{.os}}-{{.arch}}",
+		},
+		Args: Args{
+			Source:  Patterns{"multiple-matched/*.{zip,tar.gz}"},
+			Pattern: "{artifact}-{os}-{arch}-{version}.{extension:tar.gz|zip}",
+			Debug:   true,
+		},
+		workspacePath: "test-data/",
+		log:           &log,
+		output:        &bytes.Buffer{},
+	}
+	err := mvn.Prepare()
+	if err != nil {
+		t.Fatal(err)
+	}
+	artifacts := mvn.artifacts["com.test.pattern:app-client:0.1.4"]
+	if len(artifacts) != 5 {
+		t.Fatalf("expected 5 app-client artifacts, got %v", mvn.artifactKeys())
+	}
+	found := false
+	for _, a := range artifacts {
+		if a.Classifier == "linux-amd64" && a.Extension == "tar.gz" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected linux-amd64 tar.gz artifact, got %+v", artifacts)
+	}
+	if !strings.Contains(log.String(), "pattern {artifact}-{os}-{arch}-{version}.{extension:tar.gz|zip} compiled to regexp (?:^|/)(?P<artifact>") {
+		t.Fatalf("expected compiled regexp in debug output:\n%s", log.String())
+	}
+
+	mvn.Args.Regexp = ".*"
+	if err := mvn.Prepare(); err == nil || !strings.Contains(err.Error(), "both regexp and pattern") {
+		t.Fatalf("expected regexp and pattern error, got %v", err)
+	}
+}
+
+func TestPreparePatternUnused(t *testing.T) {
+	for _, v := range []struct {
+		pattern    string
+		classifier string
+		err        string
+	}{
+		{"{artifact}-{os}-{arch}-{version}.{extension:tar.gz|zip}", "{{.os}}",
+			"placeholder {arch} of pattern '{artifact}-{os}-{arch}-{version}.{extension:tar.gz|zip}' is not used by any coordinate template"},
+		{"{artifact}-{classifier}-{version}.{extension}", "",
+			"pattern '{artifact}-{classifier}-{version}.{extension}' is ambiguous for 'multiple-matched/app-client-darwin-amd64-0.1.4.zip', it matches both artifact=app classifier=client-darwin-amd64 version=0.1.4 extension=zip and artifact=app-client-darwin classifier=amd64"},
+		{"{artifact}-{os}-*-{version}.{extension:tar.gz|zip}", "{{.os}}",
+			"it matches both artifact=app os=client version=0.1.4 extension=zip and artifact=app-client os=darwin"},
+	} {
+		mvn := &Maven{
+			Artifact: Artifact{
+				GroupID:    "com.test.pattern",
+				Classifier: v.classifier,
+			},
+			Args: Args{
+				Source:  Patterns{"multiple-matched/*.{zip,tar.gz}"},
+				Pattern: v.pattern,
+			},
+			workspacePath: "test-data/",
+			quiet:         true,
+		}
+		err := mvn.Prepare()
+		if err == nil || !strings.Contains(err.Error(), v.err) {
+			t.Fatalf("%s: expected error %q, got %v", v.pattern, v.err, err)
+		}
+	}
+}
+
+func TestPatternVersions(t *testing.T) {
+	expr, err := compilePattern("{artifact}-{version}.{extension}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	re := regexp.MustCompile(expr)
+	for _, v := range []struct {
+		name, artifact, version, extension string
+	}{
+		{"app-1.0.0-beta-2.zip", "app", "1.0.0-beta-2", "zip"},
+		{"app-1.2.3-1.zip", "app", "1.2.3-1", "zip"},
+		{"app-client-1.2.3-20240101.tar.gz", "app-client", "1.2.3-20240101", "tar.gz"},
+		{"app-client-1.0-SNAPSHOT.jar", "app-client", "1.0-SNAPSHOT", "jar"},
+		{"app-2.0.0-rc.1.tar.gz", "app", "2.0.0-rc.1", "tar.gz"},
+	} {
+		m := re.FindStringSubmatch(v.name)
+		if m == nil || m[1] != v.artifact || m[2] != v.version || m[3] != v.extension {
+			t.Fatalf("%s: expected %s %s %s, got %q", v.name, v.artifact, v.version, v.extension, m)
+		}
+	}
+}
+
+func TestPreparePatternClassifier(t *testing.T) {
+	mvn := &Maven{
+		Artifact: Artifact{
+			GroupID: "com.test.pattern",
+		},
+		Args: Args{
+			Source:  Patterns{"multiple-matched/*.{zip,tar.gz}"},
+			Pattern: "{artifact}-{os}-{arch}-{version}.{extension:tar.gz|zip}",
+		},
+		workspacePath: "test-data/",
+		quiet:         true,
+	}
+	err := mvn.Prepare()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := mvn.artifactKeys()
+	expected := []string{
+		"com.test.pattern:app-client:0.1.4",
+		"com.test.pattern:app-gui:0.1.4",
+		"com.test.pattern:app-server:0.1.4",
+	}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Fatalf("expected %v, got %v", expected, keys)
+	}
+	var classifiers []string
+	for _, a := range mvn.artifacts[expected[0]] {
+		classifiers = append(classifiers, a.Classifier+"."+a.Extension)
+	}
+	sort.Strings(classifiers)
+	if !reflect.DeepEqual(classifiers, []string{"darwin-amd64.zip", "linux-386.tar.gz", "linux-amd64.tar.gz", "windows-386.zip", "windows-amd64.zip"}) {
+		t.Fatalf("expected os-arch classifiers, got %v", classifiers)
+	}
+}
@@ -0,0 +1,381 @@
+package mavendeploy
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// Project is the root of a maven pom.xml file.
+type Project struct {
+	XMLName         xml.Name         `xml:"project"`
+	Xmlns           string           `xml:"xmlns,attr"`
+	XmlnsXsi        string           `xml:"xmlns:xsi,attr"`
+	SchemaLocation  string           `xml:"xsi:schemaLocation,attr"`
+	ModelVersion    string           `xml:"modelVersion"`
+	GroupID         string           `xml:"groupId"`
+	ArtifactID      string           `xml:"artifactId"`
+	Version         string           `xml:"version"`
+	Packaging       string           `xml:"packaging,omitempty"`
+	Name            string           `xml:"name,omitempty"`
+	Description     string           `xml:"description,omitempty"`
+	URL             string           `xml:"url,omitempty"`
+	Organization    *Organization    `xml:"organization,omitempty"`
+	Licenses        *Licenses        `xml:"licenses,omitempty"`
+	Developers      *Developers      `xml:"developers,omitempty"`
+	SCM             *SCM             `xml:"scm,omitempty"`
+	IssueManagement *IssueManagement `xml:"issueManagement,omitempty"`
+	Dependencies    *Dependencies    `xml:"dependencies,omitempty"`
+}
+
+// Licenses is the licenses section of a pom.
+type Licenses struct {
+	License []License `xml:"license"`
+}
+
+// License is a pom license.
+type License struct {
+	Name         string `json:"name" xml:"name,omitempty"`
+	URL          string `json:"url" xml:"url,omitempty"`
+	Distribution string `json:"distribution" xml:"distribution,omitempty"` // repo or manual
+	Comments     string `json:"comments" xml:"comments,omitempty"`
+}
+
+// Developers is the developers section of a pom.
+type Developers struct {
+	Developer []Developer `xml:"developer"`
+}
+
+// Developer is a pom developer.
+type Developer struct {
+	ID              string `json:"id" xml:"id,omitempty"`
+	Name            string `json:"name" xml:"name,omitempty"`
+	Email           string `json:"email" xml:"email,omitempty"`
+	URL             string `json:"url" xml:"url,omitempty"`
+	Organization    string `json:"organization" xml:"organization,omitempty"`
+	OrganizationURL string `json:"organization_url" xml:"organizationUrl,omitempty"`
+}
+
+// Organization is the pom organization.
+type Organization struct {
+	Name string `json:"name" xml:"name,omitempty"`
+	URL  string `json:"url" xml:"url,omitempty"`
+}
+
+// SCM is the pom source control information.
+type SCM struct {
+	URL                 string `json:"url" xml:"url,omitempty"`
+	Connection          string `json:"connection" xml:"connection,omitempty"`
+	DeveloperConnection string `json:"developer_connection" xml:"developerConnection,omitempty"`
+	Tag                 string `json:"tag" xml:"tag,omitempty"`
+}
+
+// IssueManagement is the pom issue tracker information.
+type IssueManagement struct {
+	System string `json:"system" xml:"system,omitempty"`
+	URL    string `json:"url" xml:"url,omitempty"`
+}
+
+// Dependencies is the dependencies section of a pom.
+type Dependencies struct {
+	Dependency []Dependency `xml:"dependency"`
+}
+
+// Dependency is a pom dependency.
+//
+// A dependency without group and version refers to an artifact published in
+// the same step by its artifact id, the group and version are then taken from
+// that artifact.
+type Dependency struct {
+	GroupID    string `json:"group" xml:"groupId"`
+	ArtifactID string `json:"artifact" xml:"artifactId"`
+	Version    string `json:"version" xml:"version"`
+	Classifier string `json:"classifier" xml:"classifier,omitempty"`
+	Type       string `json:"type" xml:"type,omitempty"`   // e.g. jar, zip, pom
+	Scope      string `json:"scope" xml:"scope,omitempty"` // e.g. compile, runtime
+	Optional   bool   `json:"optional" xml:"optional,omitempty"`
+}
+
+// defaultDescription is the description of generated poms without one, like
+// the one generated by maven-deploy-plugin:deploy-file it doesn't describe
+// the project.
+const defaultDescription = "POM was created by drone-mvn"
+
+// newProject returns the pom for the artifact. Without any descriptive
+// information it is equivalent to the one generated by
+// maven-deploy-plugin:deploy-file.
+func newProject(a Artifact, info POM) *Project {
+	p := &Project{
+		Xmlns:           "http://maven.apache.org/POM/4.0.0",
+		XmlnsXsi:        "http://www.w3.org/2001/XMLSchema-instance",
+		SchemaLocation:  "http://maven.apache.org/POM/4.0.0 http://maven.apache.org/xsd/maven-4.0.0.xsd",
+		ModelVersion:    "4.0.0",
+		GroupID:         a.GroupID,
+		ArtifactID:      a.ArtifactID,
+		Version:         a.Version,
+		Packaging:       a.extension(),
+		Name:            info.Name,
+		Description:     info.Description,
+		URL:             info.URL,
+		Organization:    info.Organization,
+		SCM:             info.SCM,
+		IssueManagement: info.IssueManagement,
+	}
+	if p.Description == "" {
+		p.Description = defaultDescription
+	}
+	if len(info.Licenses) > 0 {
+		p.Licenses = &Licenses{info.Licenses}
+	}
+	if len(info.Developers) > 0 {
+		p.Developers = &Developers{info.Developers}
+	}
+	if len(info.Dependencies) > 0 {
+		p.Dependencies = &Dependencies{info.Dependencies}
+	}
+	return p
+}
+
+// Marshal returns the xml document.
+func (p *Project) Marshal() ([]byte, error) {
+	return marshalXML(p)
+}
+
+// ForArtifact returns the pom information for a, which is the default values
+// overridden by any non empty values from the matching entry in Artifacts.
+func (p POM) ForArtifact(a Artifact) POM {
+	result := p
+	result.Artifacts = nil
+	for _, key := range []string{
+		a.ArtifactID,
+		a.GroupID + ":" + a.ArtifactID,
+		a.key(),
+	} {
+		o, ok := p.Artifacts[key]
+		if !ok {
+			continue
+		}
+		if o.Name != "" {
+			result.Name = o.Name
+		}
+		if o.Description != "" {
+			result.Description = o.Description
+		}
+		if o.URL != "" {
+			result.URL = o.URL
+		}
+		if o.Organization != nil {
+			result.Organization = o.Organization
+		}
+		if o.Licenses != nil {
+			result.Licenses = o.Licenses
+		}
+		if o.Developers != nil {
+			result.Developers = o.Developers
+		}
+		if o.SCM != nil {
+			result.SCM = o.SCM
+		}
+		if o.IssueManagement != nil {
+			result.IssueManagement = o.IssueManagement
+		}
+		if o.Dependencies != nil {
+			result.Dependencies = o.Dependencies
+		}
+	}
+	return result
+}
+
+// IsZero returns true if no pom information is set.
+func (p POM) IsZero() bool {
+	return p.Name == "" &&
+		p.Description == "" &&
+		p.URL == "" &&
+		p.Organization == nil &&
+		len(p.Licenses) == 0 &&
+		len(p.Developers) == 0 &&
+		p.SCM == nil &&
+		p.IssueManagement == nil &&
+		len(p.Dependencies) == 0 &&
+		len(p.Artifacts) == 0
+}
+
+// preparePoms generates the pom of each prepared artifact group.
+func (mvn *Maven) preparePoms() error {
+	mvn.projects = make(map[string]*Project, len(mvn.artifacts))
+	for key, artifacts := range mvn.artifacts {
+		a := artifacts[0]
+		info := mvn.POM.ForArtifact(a)
+		deps, err := mvn.resolveDependencies(a, info.Dependencies)
+		if err != nil {
+			return err
+		}
+		info.Dependencies = deps
+		mvn.projects[key] = newProject(a, info)
+	}
+	return nil
+}
+
+// resolveDependencies returns the dependencies of a where references to
+// artifacts published in the same step are completed with their group,
+// version and type. A reference to a itself is left out so that the same
+// dependency list can be used for all published artifacts.
+func (mvn *Maven) resolveDependencies(a Artifact, deps []Dependency) ([]Dependency, error) {
+	var resolved []Dependency
+	for _, d := range deps {
+		if d.ArtifactID == "" {
+			return nil, fmt.Errorf("dependency %+v has no artifact", d)
+		}
+		if d.Version == "" {
+			sibling, ok := mvn.findSibling(a, d)
+			if !ok {
+				return nil, fmt.Errorf(
+					"dependency %s of %s has no version and is not published in this step",
+					d.ArtifactID, a.key())
+			}
+			d.GroupID = sibling[0].GroupID
+			d.Version = sibling[0].Version
+			if d.Type == "" {
+				d.Type = "pom"
+				for _, v := range sibling {
+					if v.Classifier == d.Classifier {
+						d.Type = v.extension()
+						break
+					}
+				}
+			}
+		}
+		if d.GroupID == "" {
+			d.GroupID = a.GroupID
+		}
+		if d.GroupID == a.GroupID && d.ArtifactID == a.ArtifactID {
+			continue
+		}
+		resolved = append(resolved, d)
+	}
+	return resolved, nil
+}
+
+// findSibling returns the prepared artifact group referenced by d,
+// preferring the group of a and the version of a when several artifact
+// groups match.
+func (mvn *Maven) findSibling(a Artifact, d Dependency) ([]Artifact, bool) {
+	var found []Artifact
+	score := -1
+	for _, key := range mvn.artifactKeys() {
+		artifacts := mvn.artifacts[key]
+		v := artifacts[0]
+		if v.ArtifactID != d.ArtifactID || (d.GroupID != "" && v.GroupID != d.GroupID) {
+			continue
+		}
+		s := 0
+		if v.GroupID == a.GroupID {
+			s += 2
+		}
+		if v.Version == a.Version {
+			s++
+		}
+		if s > score {
+			found, score = artifacts, s
+		}
+	}
+	return found, found != nil
+}
+
+// pomSource returns the pom file of the artifact group, or the generated pom
+// if no pom file is used.
+func (mvn *Maven) pomSource(key string) (filename string, data []byte, err error) {
+	if mvn.Args.PomFile != "" {
+		return mvn.pomFiles[key], nil, nil
+	}
+	data, err = mvn.projects[key].Marshal()
+	return "", data, err
+}
+
+// pomCoordinates is the subset of a pom.xml needed to validate user supplied
+// pom files.
+type pomCoordinates struct {
+	XMLName    xml.Name `xml:"project"`
+	GroupID    string   `xml:"groupId"`
+	ArtifactID string   `xml:"artifactId"`
+	Version    string   `xml:"version"`
+	Parent     struct {
+		GroupID string `xml:"groupId"`
+		Version string `xml:"version"`
+	} `xml:"parent"`
+}
+
+// readPomCoordinates reads the coordinates from a pom file, groupId and
+// version are inherited from the parent if not set.
+func readPomCoordinates(filename string) (pomCoordinates, error) {
+	var p pomCoordinates
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return p, err
+	}
+	err = xml.Unmarshal(data, &p)
+	if err != nil {
+		return p, fmt.Errorf("could not parse %s: %v", filename, err)
+	}
+	if p.GroupID == "" {
+		p.GroupID = p.Parent.GroupID
+	}
+	if p.Version == "" {
+		p.Version = p.Parent.Version
+	}
+	return p, nil
+}
+
+// preparePomFiles resolves and validates the pom file of each prepared
+// artifact group. The pom_file pattern is relative to the workspace and may
+// contain the {group}, {artifact} and {version} placeholders as well as glob
+// patterns but has to match exactly one file per artifact group.
+func (mvn *Maven) preparePomFiles() error {
+	mvn.pomFiles = make(map[string]string, len(mvn.artifacts))
+	for _, key := range mvn.artifactKeys() {
+		a := mvn.artifacts[key][0]
+		pattern := strings.NewReplacer(
+			"{group}", a.GroupID,
+			"{artifact}", a.ArtifactID,
+			"{version}", a.Version,
+		).Replace(mvn.Args.PomFile)
+		matches, err := filepath.Glob(filepath.Join(mvn.workspacePath, pattern))
+		if err != nil {
+			return err
+		}
+		switch len(matches) {
+		case 0:
+			return fmt.Errorf("no pom file found for %s using %s", key, pattern)
+		case 1:
+		default:
+			return fmt.Errorf("multiple pom files found for %s using %s: %v", key, pattern, matches)
+		}
+		p, err := readPomCoordinates(matches[0])
+		if err != nil {
+			return err
+		}
+		var diff []string
+		for _, v := range []struct{ name, pom, expected string }{
+			{"groupId", p.GroupID, a.GroupID},
+			{"artifactId", p.ArtifactID, a.ArtifactID},
+			{"version", p.Version, a.Version},
+		} {
+			if v.pom != v.expected {
+				diff = append(diff,
+					fmt.Sprintf("- <%s>%s</%s>", v.name, v.pom, v.name),
+					fmt.Sprintf("+ <%s>%s</%s>", v.name, v.expected, v.name))
+			}
+		}
+		if len(diff) > 0 {
+			return fmt.Errorf("pom file %s does not match %s (- pom file, + expected):\n%s",
+				matches[0], key, strings.Join(diff, "\n"))
+		}
+		if mvn.Args.Debug {
+			fmt.Printf("using pom file %s for %s\n", matches[0], key)
+		}
+		mvn.pomFiles[key] = matches[0]
+	}
+	return nil
+}
@@ -0,0 +1,321 @@
+package mavendeploy
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPOMForArtifact(t *testing.T) {
+	pom := POM{
+		Name:        "app",
+		Description: "the app",
+		Licenses:    []License{{Name: "MIT"}},
+		Artifacts: map[string]POM{
+			"app-gui":                    {Name: "app gui"},
+			"com.test:app-gui":           {Description: "the app gui"},
+			"com.test:app-gui:1.0.0":     {Licenses: []License{{Name: "GPL"}}},
+			"com.other:app-server:1.0.0": {Name: "other"},
+		},
+	}
+	gui := pom.ForArtifact(Artifact{GroupID: "com.test", ArtifactID: "app-gui", Version: "1.0.0"})
+	expected := POM{
+		Name:        "app gui",
+		Description: "the app gui",
+		Licenses:    []License{{Name: "GPL"}},
+	}
+	if !reflect.DeepEqual(gui, expected) {
+		t.Fatalf("unexpected pom %+v", gui)
+	}
+	server := pom.ForArtifact(Artifact{GroupID: "com.test", ArtifactID: "app-server", Version: "1.0.0"})
+	expected = POM{
+		Name:        "app",
+		Description: "the app",
+		Licenses:    []License{{Name: "MIT"}},
+	}
+	if !reflect.DeepEqual(server, expected) {
+		t.Fatalf("unexpected pom %+v", server)
+	}
+}
+
+func TestNativePOM(t *testing.T) {
+	l := LocalTest{
+		t,
+		&Maven{
+			Repository: Repository{
+				Username: "u",
+				Password: "p",
+			},
+			Artifact: Artifact{
+				GroupID: "com.test.pom",
+			},
+			Args: Args{
+				Source:  Patterns{"multiple-matched/app*"},
+				Regexp:  "(?P<artifact>app-[^/-]*)-(?P<classifier>[^-]*-[^-]*)-(?P<version>.*).(?P<extension>tar.gz|zip|readme)$",
+				Backend: BackendNative,
+			},
+			POM: POM{
+				Name:        "app",
+				Description: "An application",
+				URL:         "https://example.com/app",
+				Licenses: []License{
+					{Name: "MIT", URL: "https://opensource.org/licenses/MIT"},
+				},
+				Developers: []Developer{
+					{ID: "jd", Name: "John Doe", Email: "jd@example.com"},
+				},
+				Organization: &Organization{Name: "Example"},
+				SCM: &SCM{
+					URL:        "https://github.com/example/app",
+					Connection: "scm:git:https://github.com/example/app.git",
+				},
+				IssueManagement: &IssueManagement{
+					System: "GitHub",
+					URL:    "https://github.com/example/app/issues",
+				},
+				Artifacts: map[string]POM{
+					"app-gui": {Name: "app gui"},
+				},
+			},
+		}}
+
+	l.Run(func(m *Maven) {
+		err := m.Publish()
+		if err != nil {
+			t.Fatal(err)
+		}
+		tr, err := newTransport(m.Repository, m.httpClient())
+		if err != nil {
+			t.Fatal(err)
+		}
+		for artifact, name := range map[string]string{
+			"app-client": "app",
+			"app-gui":    "app gui",
+		} {
+			data, err := tr.Get("com/test/pom/" + artifact + "/0.1.4/" + artifact + "-0.1.4.pom")
+			if err != nil {
+				t.Fatal(err)
+			}
+			var p Project
+			err = xml.Unmarshal(data, &p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.GroupID != "com.test.pom" || p.ArtifactID != artifact ||
+				p.Version != "0.1.4" || p.Name != name ||
+				p.Description != "An application" ||
+				p.URL != "https://example.com/app" ||
+				p.Licenses == nil || p.Licenses.License[0].Name != "MIT" ||
+				p.Developers == nil || p.Developers.Developer[0].Email != "jd@example.com" ||
+				p.Organization == nil || p.Organization.Name != "Example" ||
+				p.SCM == nil || p.SCM.Connection != "scm:git:https://github.com/example/app.git" ||
+				p.IssueManagement == nil || p.IssueManagement.System != "GitHub" {
+				t.Fatalf("unexpected pom:\n%s", data)
+			}
+		}
+	})
+}
+
+func TestNativePOMFile(t *testing.T) {
+	l := LocalTest{
+		t,
+		&Maven{
+			Repository: Repository{
+				Username: "u",
+				Password: "p",
+			},
+			Artifact: Artifact{
+				GroupID: "com.test.pomfile",
+			},
+			Args: Args{
+				Source:  Patterns{"multiple-matched/app*"},
+				Regexp:  "(?P<artifact>app-[^/-]*)-(?P<classifier>[^-]*-[^-]*)-(?P<version>.*).(?P<extension>tar.gz|zip|readme)$",
+				Backend: BackendNative,
+				PomFile: "poms/{artifact}.pom",
+			},
+		}}
+
+	l.Run(func(m *Maven) {
+		err := m.Publish()
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected, err := ioutil.ReadFile("test-data/poms/app-gui.pom")
+		if err != nil {
+			t.Fatal(err)
+		}
+		l.AssertFileContent("com/test/pomfile/app-gui/0.1.4/app-gui-0.1.4.pom", string(expected))
+	})
+}
+
+func TestPOMFileMismatch(t *testing.T) {
+	l := LocalTest{
+		t,
+		&Maven{
+			Repository: Repository{
+				Username: "u",
+				Password: "p",
+			},
+			Artifact: Artifact{
+				GroupID:    "com.test.pomfile",
+				ArtifactID: "release",
+				Extension:  "zip",
+				Version:    "1.2.4",
+			},
+			Args: Args{
+				Source:  Patterns{"single/release.zip"},
+				Backend: BackendNative,
+				PomFile: "poms/release.pom",
+			},
+		}}
+
+	l.Run(func(m *Maven) {
+		err := m.Publish()
+		if err == nil {
+			t.Fatal("expected pom file mismatch")
+		}
+		if !strings.Contains(err.Error(), "- <version>1.2.3</version>\n+ <version>1.2.4</version>") {
+			t.Fatalf("expected version diff, got %v", err)
+		}
+		l.AssertNoFiles()
+	})
+}
+
+func TestPOMFilePlaceholderMismatch(t *testing.T) {
+	l := LocalTest{
+		t,
+		&Maven{
+			Repository: Repository{
+				Username: "u",
+				Password: "p",
+			},
+			Artifact: Artifact{
+				GroupID: "com.test.other",
+			},
+			Args: Args{
+				Source:  Patterns{"multiple-matched/app-gui*"},
+				Regexp:  "(?P<artifact>app-[^/-]*)-(?P<classifier>[^-]*-[^-]*)-(?P<version>.*).(?P<extension>tar.gz|zip|readme)$",
+				Backend: BackendNative,
+				PomFile: "poms/{artifact}.pom",
+			},
+		}}
+
+	l.Run(func(m *Maven) {
+		// the placeholders select the pom file but its contents are
+		// published as is
+		err := m.Publish()
+		if err == nil {
+			t.Fatal("expected pom file mismatch")
+		}
+		if !strings.Contains(err.Error(), "poms/app-gui.pom does not match com.test.other:app-gui:0.1.4") ||
+			!strings.Contains(err.Error(), "- <groupId>com.test.pomfile</groupId>\n+ <groupId>com.test.other</groupId>") {
+			t.Fatalf("expected groupId diff, got %v", err)
+		}
+		l.AssertNoFiles()
+	})
+}
+
+func TestNativePOMDependencies(t *testing.T) {
+	l := LocalTest{
+		t,
+		&Maven{
+			Repository: Repository{
+				Username: "u",
+				Password: "p",
+			},
+			Artifact: Artifact{
+				GroupID: "com.test.deps",
+			},
+			Args: Args{
+				Source:  Patterns{"multiple-matched/app*"},
+				Regexp:  "(?P<artifact>app-[^/-]*)-(?P<classifier>[^-]*-[^-]*)-(?P<version>.*).(?P<extension>tar.gz|zip|readme)$",
+				Backend: BackendNative,
+			},
+			POM: POM{
+				Dependencies: []Dependency{
+					{ArtifactID: "app-server"},
+				},
+				Artifacts: map[string]POM{
+					"app-gui": {
+						Dependencies: []Dependency{
+							{ArtifactID: "app-client", Classifier: "darwin-amd64"},
+							{GroupID: "com.acme", ArtifactID: "config", Version: "1.0", Type: "zip", Scope: "runtime", Optional: true},
+						},
+					},
+				},
+			},
+		}}
+
+	l.Run(func(m *Maven) {
+		err := m.Publish()
+		if err != nil {
+			t.Fatal(err)
+		}
+		tr, err := newTransport(m.Repository, m.httpClient())
+		if err != nil {
+			t.Fatal(err)
+		}
+		for artifact, expected := range map[string][]Dependency{
+			"app-client": {
+				{GroupID: "com.test.deps", ArtifactID: "app-server", Version: "0.1.4", Type: "pom"},
+			},
+			"app-gui": {
+				{GroupID: "com.test.deps", ArtifactID: "app-client", Version: "0.1.4", Classifier: "darwin-amd64", Type: "zip"},
+				{GroupID: "com.acme", ArtifactID: "config", Version: "1.0", Type: "zip", Scope: "runtime", Optional: true},
+			},
+			"app-server": nil,
+		} {
+			data, err := tr.Get("com/test/deps/" + artifact + "/0.1.4/" + artifact + "-0.1.4.pom")
+			if err != nil {
+				t.Fatal(err)
+			}
+			var p Project
+			err = xml.Unmarshal(data, &p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var deps []Dependency
+			if p.Dependencies != nil {
+				deps = p.Dependencies.Dependency
+			}
+			if !reflect.DeepEqual(deps, expected) {
+				t.Fatalf("unexpected dependencies in %s:\n%s", artifact, data)
+			}
+		}
+	})
+}
+
+func TestPOMDependencyNotPublished(t *testing.T) {
+	l := LocalTest{
+		t,
+		&Maven{
+			Repository: Repository{
+				Username: "u",
+				Password: "p",
+			},
+			Artifact: Artifact{
+				GroupID:    "com.test.deps",
+				ArtifactID: "release",
+				Version:    "1.2.3",
+			},
+			Args: Args{
+				Source:  Patterns{"single/release.zip"},
+				Backend: BackendNative,
+			},
+			POM: POM{
+				Dependencies: []Dependency{
+					{ArtifactID: "app-client"},
+				},
+			},
+		}}
+
+	l.Run(func(m *Maven) {
+		err := m.Publish()
+		if err == nil {
+			t.Fatal("expected unresolved dependency error")
+		}
+		l.AssertNoFiles()
+	})
+}
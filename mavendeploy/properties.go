@@ -0,0 +1,67 @@
+package mavendeploy
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// propertiesTransport is implemented by transports which can attach
+// properties to uploaded files, like Artifactory does with matrix
+// parameters.
+type propertiesTransport interface {
+	// PutWithProperties writes size bytes read from r to p with properties.
+	PutWithProperties(p string, r io.Reader, size int64, properties string) error
+}
+
+// prepareProperties expands the property templates. When any properties are
+// configured the vcs.revision and build.number properties of the build are
+// added unless they are configured explicitly.
+func (mvn *Maven) prepareProperties() error {
+	mvn.properties = nil
+	if mvn.Args.Properties == nil {
+		return nil
+	}
+	props := make(map[string]string)
+	if mvn.build.Commit != "" {
+		props["vcs.revision"] = mvn.build.Commit
+	}
+	if mvn.build.Number != 0 {
+		props["build.number"] = strconv.Itoa(mvn.build.Number)
+	}
+	data := mvn.templateData(nil)
+	for k, v := range mvn.Args.Properties {
+		if k == "" {
+			return fmt.Errorf("property name is %s", errRequiredValue)
+		}
+		value, err := expandTemplate("property "+k, v, data)
+		if err != nil {
+			return err
+		}
+		props[k] = value
+	}
+	mvn.properties = props
+	return nil
+}
+
+// matrixParams returns the properties as matrix parameters sorted by name,
+// e.g. ";build.number=22;vcs.revision=9f2849d5".
+func (mvn *Maven) matrixParams() string {
+	var keys []string
+	for k := range mvn.properties {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var params []string
+	for _, k := range keys {
+		params = append(params, ";"+url.PathEscape(k)+"="+url.PathEscape(mvn.properties[k]))
+	}
+	return strings.Join(params, "")
+}
+
+func (h *httpTransport) PutWithProperties(p string, r io.Reader, size int64, properties string) error {
+	return h.Put(p+properties, r, size)
+}
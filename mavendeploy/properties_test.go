@@ -0,0 +1,94 @@
+package mavendeploy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/drone/drone-plugin-go/plugin"
+)
+
+func TestProperties(t *testing.T) {
+	repo := newFakeRepo()
+	var mu sync.Mutex
+	properties := make(map[string]string) // matrix parameters by file name
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if p := r.URL.EscapedPath(); r.Method == "PUT" && strings.Contains(p, ";") {
+			i := strings.Index(p, ";")
+			mu.Lock()
+			properties[path.Base(p[:i])] = p[i:]
+			mu.Unlock()
+			r.URL.Path = r.URL.Path[:strings.Index(r.URL.Path, ";")]
+		}
+		repo.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+	mvn := &Maven{
+		Repository: Repository{
+			Username: "u",
+			Password: "p",
+			URL:      server.URL,
+		},
+		Artifact: Artifact{
+			GroupID:    "com.test.properties",
+			ArtifactID: "release",
+			Version:    "1.2.3",
+		},
+		GPG: GPG{
+			PrivateKey: privateKey,
+			Passphrase: `test`,
+		},
+		Args: Args{
+			Source:  Patterns{"single/release.zip"},
+			Backend: BackendNative,
+			Properties: map[string]string{
+				"retention": "keep {{.BuildNumber}}",
+				"branch":    "{{.Branch}}",
+			},
+		},
+		workspacePath: "test-data/",
+		quiet:         true,
+	}
+	mvn.BuildInfo(plugin.Build{Number: 22, Commit: "9f2849d5a1e2", Branch: "feature/x"}, plugin.Repo{})
+	err := mvn.Publish()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var files []string
+	for k, v := range properties {
+		files = append(files, k)
+		if v != ";branch=feature%2Fx;build.number=22;retention=keep%2022;vcs.revision=9f2849d5a1e2" {
+			t.Fatalf("unexpected properties %s of %s", v, k)
+		}
+	}
+	sort.Strings(files)
+	expected := []string{"release-1.2.3.pom", "release-1.2.3.pom.asc", "release-1.2.3.zip", "release-1.2.3.zip.asc"}
+	if !reflect.DeepEqual(files, expected) {
+		t.Fatalf("expected properties on %v, got %v", expected, files)
+	}
+}
+
+func TestPropertiesInvalid(t *testing.T) {
+	mvn := &Maven{
+		Artifact: Artifact{
+			GroupID:    "com.test.properties",
+			ArtifactID: "release",
+			Version:    "1.2.3",
+		},
+		Args: Args{
+			Source:     Patterns{"single/release.zip"},
+			Properties: map[string]string{"build.name": "{{.NoSuchField}}"},
+		},
+		workspacePath: "test-data/",
+		quiet:         true,
+	}
+	err := mvn.Prepare()
+	if err == nil || !strings.Contains(err.Error(), "property build.name template") {
+		t.Fatalf("expected template error, got %v", err)
+	}
+}
@@ -0,0 +1,200 @@
+package mavendeploy
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// Report lists the files of the prepared artifacts and where they are, or
+// would be, deployed.
+type Report struct {
+	Deployed  bool             `json:"deployed"` // false for plans and dry runs
+	Artifacts []ReportArtifact `json:"artifacts"`
+}
+
+// ReportArtifact is a file in a Report, either an artifact or the pom of a
+// groupID:artifactID:version which has the pom extension.
+type ReportArtifact struct {
+	GroupID      string            `json:"group"`
+	ArtifactID   string            `json:"artifact"`
+	Version      string            `json:"version"`
+	Classifier   string            `json:"classifier"`
+	Extension    string            `json:"extension"`
+	File         string            `json:"file"` // workspace relative, empty for generated poms
+	Size         int64             `json:"size"`
+	Checksums    map[string]string `json:"checksums"` // hex encoded checksums by algorithm
+	URL          string            `json:"url"`
+	SignatureURL string            `json:"signature_url,omitempty"`
+}
+
+// upload is a file uploaded, or in dry run mode would have been uploaded, to
+// the repository.
+type upload struct {
+	Source    string            // local file or a description of generated content
+	URL       string            // remote location
+	Size      int64             // content length
+	Checksums map[string]string // hex encoded checksums by algorithm
+}
+
+// Plan returns the report of the artifacts found by Prepare without deploying
+// anything. Snapshots are listed using their non unique version.
+func (mvn *Maven) Plan() (*Report, error) {
+	if mvn.artifacts == nil {
+		return nil, errors.New("no artifacts prepared")
+	}
+	return mvn.report(false)
+}
+
+// report builds the report of the prepared artifacts. Sizes and checksums
+// are taken from the uploads when available and otherwise calculated from the
+// local files.
+func (mvn *Maven) report(deployed bool) (*Report, error) {
+	d := mvn.deployment
+	if d == nil {
+		d = newDeployment()
+	}
+	uploaded := make(map[string]upload, len(d.uploads))
+	for _, v := range d.uploads {
+		uploaded[v.URL] = v
+	}
+	r := &Report{
+		Deployed: deployed && !mvn.Args.DryRun,
+	}
+	for _, key := range mvn.artifactKeys() {
+		var t transport
+		if mvn.hasTarget() {
+			var err error
+			t, err = mvn.transport(mvn.artifacts[key][0])
+			if err != nil {
+				return nil, err
+			}
+		}
+		unique := d.uniqueVersions[key]
+		for _, a := range mvn.artifacts[key] {
+			a.uniqueVersion = unique
+			e := ReportArtifact{
+				GroupID:    a.GroupID,
+				ArtifactID: a.ArtifactID,
+				Version:    a.Version,
+				Classifier: a.Classifier,
+				Extension:  a.extension(),
+				File:       mvn.relPath(a.file),
+			}
+			err := mvn.describe(&e, t, uploaded, path.Join(a.versionDir(), a.fileName()), a.file, nil)
+			if err != nil {
+				return nil, err
+			}
+			r.Artifacts = append(r.Artifacts, e)
+		}
+		a := mvn.artifacts[key][0]
+		a.uniqueVersion = unique
+		e := ReportArtifact{
+			GroupID:    a.GroupID,
+			ArtifactID: a.ArtifactID,
+			Version:    a.Version,
+			Extension:  "pom",
+		}
+		filename, data, err := mvn.pomSource(key)
+		if err != nil {
+			return nil, err
+		}
+		e.File = mvn.relPath(filename)
+		err = mvn.describe(&e, t, uploaded, path.Join(a.versionDir(), a.pomName()), filename, data)
+		if err != nil {
+			return nil, err
+		}
+		r.Artifacts = append(r.Artifacts, e)
+	}
+	return r, nil
+}
+
+// describe sets the locations, size and checksums of e which is stored at
+// the repository path p and read from either data or filename.
+func (mvn *Maven) describe(e *ReportArtifact, t transport, uploaded map[string]upload, p, filename string, data []byte) error {
+	e.URL = p
+	if t != nil {
+		e.URL = t.URL(p)
+	}
+	if mvn.GPG.PrivateKey != "" {
+		e.SignatureURL = e.URL + ".asc"
+	}
+	if u, ok := uploaded[e.URL]; ok {
+		e.Size, e.Checksums = u.Size, u.Checksums
+		return nil
+	}
+	var err error
+	e.Size, e.Checksums, err = hashSource(filename, data, mvn.checksums())
+	return err
+}
+
+// hashSource returns the size and the hex encoded checksums by algorithm of
+// data, or the file filename if data is nil.
+func hashSource(filename string, data []byte, exts []string) (int64, map[string]string, error) {
+	var r io.Reader = bytes.NewReader(data)
+	if data == nil {
+		f, err := os.Open(filename)
+		if err != nil {
+			return 0, nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	hashes, w, err := newHashes(exts)
+	if err != nil {
+		return 0, nil, err
+	}
+	n, err := io.Copy(w, r)
+	if err != nil {
+		return 0, nil, err
+	}
+	sums := make(map[string]string, len(exts))
+	for i, h := range hashes {
+		sums[exts[i]] = hex.EncodeToString(h.Sum(nil))
+	}
+	return n, sums, nil
+}
+
+// writeReport writes the report of the deployed artifacts to the report
+// path, relative to the workspace, if set.
+func (mvn *Maven) writeReport() error {
+	if mvn.Args.Report == "" {
+		return nil
+	}
+	r, err := mvn.report(true)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	filename := mvn.Args.Report
+	if !filepath.IsAbs(filename) {
+		filename = filepath.Join(mvn.workspacePath, filename)
+	}
+	err = ioutil.WriteFile(filename, append(data, '\n'), 0644)
+	if err != nil {
+		return err
+	}
+	mvn.infof("wrote report %s", filename)
+	return nil
+}
+
+// relPath returns filename relative to the workspace if possible.
+func (mvn *Maven) relPath(filename string) string {
+	if filename == "" {
+		return ""
+	}
+	rel, err := filepath.Rel(mvn.workspacePath, filename)
+	if err != nil {
+		return filename
+	}
+	return rel
+}
@@ -0,0 +1,147 @@
+package mavendeploy
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNativeReport(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "drone-mvn-report")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpdir)
+	filename := filepath.Join(tmpdir, "report.json")
+
+	l := LocalTest{
+		t,
+		&Maven{
+			Repository: Repository{
+				Username: "u",
+				Password: "p",
+			},
+			Artifact: Artifact{
+				GroupID:    "com.test.report",
+				ArtifactID: "release",
+				Extension:  "zip",
+				Version:    "1.2.3-SNAPSHOT",
+			},
+			GPG: GPG{
+				PrivateKey: privateKey,
+				Passphrase: `test`,
+			},
+			Args: Args{
+				Source:  Patterns{"single/release.zip"},
+				Backend: BackendNative,
+				Report:  filename,
+			},
+		}}
+
+	l.Run(func(m *Maven) {
+		now := time.Date(2015, 10, 31, 12, 0, 0, 0, time.UTC)
+		m.clock = func() time.Time { return now }
+		err := m.Publish()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := ioutil.ReadFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var r Report
+		err = json.Unmarshal(data, &r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !r.Deployed || len(r.Artifacts) != 2 {
+			t.Fatalf("unexpected report:\n%s", data)
+		}
+		dir := m.Repository.URL + "/com/test/report/release/1.2.3-SNAPSHOT/"
+		expected := ReportArtifact{
+			GroupID:    "com.test.report",
+			ArtifactID: "release",
+			Version:    "1.2.3-SNAPSHOT",
+			Extension:  "zip",
+			File:       "single/release.zip",
+			Size:       2,
+			Checksums: map[string]string{
+				"md5":  "60b725f10c9c85c70d97880dfe8191b3",
+				"sha1": "3f786850e387550fdab836ed7e6dc881de23001b",
+			},
+			URL:          dir + "release-1.2.3-20151031.120000-1.zip",
+			SignatureURL: dir + "release-1.2.3-20151031.120000-1.zip.asc",
+		}
+		if !reflect.DeepEqual(r.Artifacts[0], expected) {
+			t.Fatalf("unexpected artifact:\n%+v\nexpected:\n%+v", r.Artifacts[0], expected)
+		}
+		pom := r.Artifacts[1]
+		if pom.Extension != "pom" || pom.File != "" ||
+			pom.URL != dir+"release-1.2.3-20151031.120000-1.pom" ||
+			len(pom.Checksums) != 2 {
+			t.Fatalf("unexpected pom: %+v", pom)
+		}
+	})
+}
+
+func TestPlan(t *testing.T) {
+	mvn := &Maven{
+		Repository: Repository{
+			URL: "https://repo.example.com/releases/",
+		},
+		Artifact: Artifact{
+			GroupID:    "com.test.plan",
+			ArtifactID: "release",
+			Extension:  "zip",
+			Version:    "1.2.3",
+		},
+		Args: Args{
+			Source:    Patterns{"single/release.zip"},
+			Checksums: []string{"sha256"},
+		},
+		workspacePath: "test-data/",
+		quiet:         true,
+	}
+	_, err := mvn.Plan()
+	if err == nil {
+		t.Fatal("expected error before Prepare")
+	}
+	err = mvn.Prepare()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mvn.Args.Checksums = []string{"SHA-256"}
+	if _, err := mvn.Plan(); err == nil || err.Error() != "checksum SHA-256 is invalid" {
+		t.Fatalf("expected invalid checksum error, got %v", err)
+	}
+	mvn.Args.Checksums = []string{"sha256"}
+	r, err := mvn.Plan()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Deployed || len(r.Artifacts) != 2 {
+		t.Fatalf("unexpected plan: %+v", r)
+	}
+	expected := ReportArtifact{
+		GroupID:    "com.test.plan",
+		ArtifactID: "release",
+		Version:    "1.2.3",
+		Extension:  "zip",
+		File:       "single/release.zip",
+		Size:       2,
+		Checksums: map[string]string{
+			"sha256": "87428fc522803d31065e7bce3cf03fe475096631e5e07bbd7a0fde60c4cf25c7",
+		},
+		URL: "https://repo.example.com/releases/com/test/plan/release/1.2.3/release-1.2.3.zip",
+	}
+	if !reflect.DeepEqual(r.Artifacts[0], expected) {
+		t.Fatalf("unexpected artifact:\n%+v\nexpected:\n%+v", r.Artifacts[0], expected)
+	}
+	if r.Artifacts[1].URL != "https://repo.example.com/releases/com/test/plan/release/1.2.3/release-1.2.3.pom" {
+		t.Fatalf("unexpected pom: %+v", r.Artifacts[1])
+	}
+}
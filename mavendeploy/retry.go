@@ -0,0 +1,183 @@
+package mavendeploy
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Retry configures retries of failed deployments. Each artifact group
+// (groupID:artifactID:version) is retried separately with an exponentially
+// increasing delay.
+type Retry struct {
+	Attempts int     `json:"attempts"`  // attempts per artifact group, 1 (default) disables retries
+	Delay    string  `json:"delay"`     // delay before the first retry, e.g. 500ms, default 1s
+	MaxDelay string  `json:"max_delay"` // maximum delay between attempts, default 1m
+	Jitter   float64 `json:"jitter"`    // fraction of the delay randomly added or subtracted, 0 to 1
+	Timeout  string  `json:"timeout"`   // maximum duration of each http request, default 10m
+}
+
+// Retry defaults.
+const (
+	defaultRetryDelay    = time.Second
+	defaultRetryMaxDelay = time.Minute
+	defaultRetryTimeout  = 10 * time.Minute
+)
+
+func (r Retry) validate() error {
+	if r.Attempts < 0 {
+		return fmt.Errorf("retry attempts %d is %s", r.Attempts, errInvalidValue)
+	}
+	if r.Jitter < 0 || r.Jitter > 1 {
+		return fmt.Errorf("retry jitter %v is %s, must be between 0 and 1", r.Jitter, errInvalidValue)
+	}
+	for _, v := range []string{r.Delay, r.MaxDelay} {
+		if v == "" {
+			continue
+		}
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			return fmt.Errorf("retry delay %s is %s", v, errInvalidValue)
+		}
+	}
+	if r.Timeout != "" {
+		d, err := time.ParseDuration(r.Timeout)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("retry timeout %s is %s", r.Timeout, errInvalidValue)
+		}
+	}
+	return nil
+}
+
+// timeout returns the maximum duration of each http request.
+func (r Retry) timeout() time.Duration {
+	if d, err := time.ParseDuration(r.Timeout); err == nil && d > 0 {
+		return d
+	}
+	return defaultRetryTimeout
+}
+
+// backoff returns the delay after the failed attempt n, starting at 1.
+func (r Retry) backoff(n int) time.Duration {
+	delay, maxDelay := defaultRetryDelay, defaultRetryMaxDelay
+	if d, err := time.ParseDuration(r.Delay); err == nil {
+		delay = d
+	}
+	if d, err := time.ParseDuration(r.MaxDelay); err == nil {
+		maxDelay = d
+	}
+	for i := 1; i < n && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+	if r.Jitter > 0 {
+		delay += time.Duration((rand.Float64()*2 - 1) * r.Jitter * float64(delay))
+	}
+	return delay
+}
+
+// retry calls f until it succeeds, fails with an error which isn't
+// retryable or the configured number of attempts are used.
+func (mvn *Maven) retry(key string, f func() error) error {
+	attempts := mvn.Args.Retry.Attempts
+	if attempts < 1 {
+		attempts = 1
+	}
+	for n := 1; ; n++ {
+		err := f()
+		if err == nil {
+			return nil
+		}
+		if !isRetryable(err) {
+			if attempts > 1 {
+				mvn.infof("attempt %d/%d for %s failed: %v, not retrying", n, attempts, key, err)
+			}
+			return err
+		}
+		if n >= attempts {
+			if attempts > 1 {
+				mvn.infof("attempt %d/%d for %s failed: %v, giving up", n, attempts, key, err)
+			}
+			return err
+		}
+		delay := mvn.Args.Retry.backoff(n)
+		mvn.infof("attempt %d/%d for %s failed: %v, retrying in %s", n, attempts, key, err, delay)
+		mvn.pause(delay)
+	}
+}
+
+// pause sleeps for d.
+func (mvn *Maven) pause(d time.Duration) {
+	if mvn.sleep != nil {
+		mvn.sleep(d)
+	} else {
+		time.Sleep(d)
+	}
+}
+
+// isRetryable returns true for errors which are likely to be temporary,
+// network errors and 5xx or 429 responses from the repository. Other
+// responses such as 400, 401 and 403 won't change by trying again.
+func isRetryable(err error) bool {
+	var herr *httpError
+	if errors.As(err, &herr) {
+		return isRetryableStatus(herr.StatusCode)
+	}
+	var merr *mvnError
+	if errors.As(err, &merr) {
+		return merr.retryable()
+	}
+	var nerr net.Error
+	return errors.As(err, &nerr)
+}
+
+func isRetryableStatus(code int) bool {
+	return code >= 500 || code == 429
+}
+
+// mvnError is a failed mvn command.
+type mvnError struct {
+	err    error
+	output string
+}
+
+func (e *mvnError) Error() string {
+	return e.err.Error()
+}
+
+// mvnStatusRe matches the http status code reported by maven-deploy-plugin
+// when a transfer fails, e.g. "Return code is: 502, ReasonPhrase: Bad
+// Gateway."
+var mvnStatusRe = regexp.MustCompile(`(?:Return code is|status code): (\d{3})`)
+
+// mvnNetworkErrors are the java exceptions of failed transfers caused by
+// network errors.
+var mvnNetworkErrors = []string{
+	"java.net.ConnectException",
+	"java.net.SocketException",
+	"java.net.SocketTimeoutException",
+	"NoHttpResponseException",
+	"Connection reset",
+	"Connection refused",
+}
+
+// retryable classifies the failure from the mvn output.
+func (e *mvnError) retryable() bool {
+	if m := mvnStatusRe.FindStringSubmatch(e.output); m != nil {
+		code, _ := strconv.Atoi(m[1])
+		return isRetryableStatus(code)
+	}
+	for _, v := range mvnNetworkErrors {
+		if strings.Contains(e.output, v) {
+			return true
+		}
+	}
+	return false
+}
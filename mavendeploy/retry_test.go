@@ -0,0 +1,194 @@
+package mavendeploy
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRetryBackoff(t *testing.T) {
+	r := Retry{Delay: "1s", MaxDelay: "5s"}
+	var delays []time.Duration
+	for n := 1; n <= 5; n++ {
+		delays = append(delays, r.backoff(n))
+	}
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	if !reflect.DeepEqual(delays, expected) {
+		t.Fatalf("expected %v, got %v", expected, delays)
+	}
+	r.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		d := r.backoff(2)
+		if d < time.Second || d > 3*time.Second {
+			t.Fatalf("delay %s out of jitter range", d)
+		}
+	}
+}
+
+func TestRetryInvalid(t *testing.T) {
+	for _, v := range []Retry{
+		{Attempts: -1},
+		{Jitter: 2},
+		{Delay: "soon"},
+		{MaxDelay: "-1s"},
+		{Timeout: "0s"},
+	} {
+		if err := v.validate(); err == nil {
+			t.Errorf("expected error for %+v", v)
+		}
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	for _, v := range []struct {
+		err       error
+		retryable bool
+	}{
+		{&httpError{StatusCode: 502}, true},
+		{&httpError{StatusCode: 503}, true},
+		{&httpError{StatusCode: 429}, true},
+		{&httpError{StatusCode: 400}, false},
+		{&httpError{StatusCode: 401}, false},
+		{&httpError{StatusCode: 403}, false},
+		{&net.OpError{Op: "dial", Err: errors.New("connection refused")}, true},
+		{errors.New("no such file"), false},
+		{&mvnError{errors.New("exit status 1"), "Failed to deploy artifacts: Could not transfer artifact t:t:zip:1.0 from/to deploy-repo (https://repo): Return code is: 502, ReasonPhrase: Bad Gateway."}, true},
+		{&mvnError{errors.New("exit status 1"), "Failed to deploy artifacts: Could not transfer artifact t:t:zip:1.0 from/to deploy-repo (https://repo): Return code is: 401, ReasonPhrase: Unauthorized."}, false},
+		{&mvnError{errors.New("exit status 1"), "Caused by: java.net.SocketException: Connection reset"}, true},
+		{&mvnError{errors.New("exit status 1"), "The parameters 'file' for goal deploy-file are missing or invalid"}, false},
+	} {
+		if isRetryable(v.err) != v.retryable {
+			t.Errorf("expected retryable %v for %v", v.retryable, v.err)
+		}
+	}
+}
+
+func TestNativeRetry(t *testing.T) {
+	for _, v := range []struct {
+		status   int // status of the first failed requests
+		failures int
+		attempts int // configured attempts
+		expected int // expected attempts of the first PUT
+		success  bool
+	}{
+		{http.StatusBadGateway, 2, 3, 3, true},
+		{http.StatusTooManyRequests, 1, 3, 2, true},
+		{http.StatusBadGateway, 3, 3, 3, false},
+		{http.StatusForbidden, 1, 3, 1, false},
+		{http.StatusBadGateway, 1, 0, 1, false},
+	} {
+		repo := newFakeRepo()
+		var mu sync.Mutex
+		var requests int
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == "PUT" {
+				mu.Lock()
+				requests++
+				n := requests
+				mu.Unlock()
+				if n <= v.failures {
+					w.WriteHeader(v.status)
+					return
+				}
+			}
+			repo.ServeHTTP(w, r)
+		}))
+		var delays []time.Duration
+		mvn := &Maven{
+			Repository: Repository{
+				Username: "u",
+				Password: "p",
+				URL:      server.URL,
+			},
+			Artifact: Artifact{
+				GroupID:    "com.test.retry",
+				ArtifactID: "release",
+				Version:    "1.2.3",
+			},
+			Args: Args{
+				Source:  Patterns{"single/release.zip"},
+				Backend: BackendNative,
+				Retry: Retry{
+					Attempts: v.attempts,
+					Delay:    "10s",
+				},
+			},
+			workspacePath: "test-data/",
+			quiet:         true,
+			sleep:         func(d time.Duration) { delays = append(delays, d) },
+		}
+		err := mvn.Publish()
+		server.Close()
+		if v.success != (err == nil) {
+			t.Fatalf("%+v: unexpected result %v", v, err)
+		}
+		attempts := len(delays) + 1
+		if attempts != v.expected {
+			t.Fatalf("%+v: expected %d attempts, got %d", v, v.expected, attempts)
+		}
+		for i, d := range delays {
+			if d != 10*time.Second<<uint(i) {
+				t.Fatalf("%+v: unexpected delays %v", v, delays)
+			}
+		}
+		if v.success && len(repo.Files()) != 9 {
+			t.Fatalf("%+v: unexpected files %v", v, repo.Files())
+		}
+	}
+}
+
+func TestNativeRetryTimeout(t *testing.T) {
+	repo := newFakeRepo()
+	var mu sync.Mutex
+	var stalled bool
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		stall := r.Method == "PUT" && !stalled
+		stalled = stalled || stall
+		mu.Unlock()
+		if stall {
+			<-release
+			return
+		}
+		repo.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+	defer close(release)
+	var delays []time.Duration
+	mvn := &Maven{
+		Repository: Repository{
+			Username: "u",
+			Password: "p",
+			URL:      server.URL,
+		},
+		Artifact: Artifact{
+			GroupID:    "com.test.retry",
+			ArtifactID: "release",
+			Version:    "1.2.3",
+		},
+		Args: Args{
+			Source:  Patterns{"single/release.zip"},
+			Backend: BackendNative,
+			Retry: Retry{
+				Attempts: 2,
+				Timeout:  "100ms",
+			},
+		},
+		workspacePath: "test-data/",
+		quiet:         true,
+		sleep:         func(d time.Duration) { delays = append(delays, d) },
+	}
+	err := mvn.Publish()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(delays) != 1 || len(repo.Files()) != 9 {
+		t.Fatalf("expected the stalled upload to be retried once, got delays %v and files %v", delays, repo.Files())
+	}
+}
@@ -0,0 +1,285 @@
+package mavendeploy
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/davecgh/go-spew/spew"
+)
+
+// Rule maps source files to artifacts. The coordinates of a rule are the
+// defaults of the artifacts parsed by it, coordinates left empty fall back to
+// the top level group, artifact, version, classifier and extension.
+//
+// Rules are evaluated in order and a source file belongs to the first rule
+// whose source patterns match it and whose regexp or pattern, if any, matches
+// its workspace relative path.
+type Rule struct {
+	Source  Patterns `json:"source"`  // source files, e.g. dist/cli-*.tar.gz
+	Exclude Patterns `json:"exclude"` // excluded in addition to the top level exclude
+	Regexp  string   `json:"regexp"`  // parses coordinates from the source path
+	Pattern string   `json:"pattern"` // alternative to regexp, see compilePattern
+
+	Artifact // default coordinates
+}
+
+// rules returns the configured rules followed by the rule formed by the top
+// level source, exclude, regexp and pattern, if a top level source is set.
+func (mvn *Maven) rules() []Rule {
+	var rules []Rule
+	for _, r := range mvn.Args.Rules {
+		r.Exclude = append(append(Patterns{}, r.Exclude...), mvn.Args.Exclude...)
+		rules = append(rules, r)
+	}
+	if len(mvn.Args.Source) > 0 || len(rules) == 0 {
+		rules = append(rules, Rule{
+			Source:  mvn.Args.Source,
+			Exclude: mvn.Args.Exclude,
+			Regexp:  mvn.Args.Regexp,
+			Pattern: mvn.Args.Pattern,
+		})
+	}
+	return rules
+}
+
+// parseSources finds the source files of all rules and parses them into
+// artifacts. The named regexp captures of each artifact are returned as
+// well.
+func (mvn *Maven) parseSources() ([]Artifact, []map[string]string, error) {
+	for i, rule := range mvn.Args.Rules {
+		if len(rule.Source) == 0 {
+			return nil, nil, fmt.Errorf("source of rule %d is %s", i+1, errRequiredValue)
+		}
+	}
+	rules := mvn.rules()
+	var (
+		parsed   []Artifact
+		captures []map[string]string
+		patterns Patterns
+	)
+	claimedBy := make(map[string]int)    // rule index by claimed source file
+	unmatched := make(map[string]string) // last regexp or pattern not matching an unclaimed source file
+	var unclaimed []string
+	for i, rule := range rules {
+		patterns = append(patterns, rule.Source...)
+		sources, err := mvn.findSources(rule.Source, rule.Exclude)
+		if err != nil {
+			return nil, nil, err
+		}
+		expr, err := mvn.ruleRegexp(rule)
+		if err != nil {
+			return nil, nil, err
+		}
+		var re, greedy *regexp.Regexp
+		var classifierParts []string // unused placeholders joined into the classifier
+		if expr != "" {
+			re, err = regexp.Compile(expr)
+			if err != nil {
+				return nil, nil, err
+			}
+			names := mvn.unusedCaptures(rule, re)
+			switch {
+			case len(names) == 0:
+			case rule.Pattern != "" && rule.Classifier == "" && mvn.Artifact.Classifier == "" &&
+				re.SubexpIndex("classifier") < 0:
+				classifierParts = names
+			case rule.Pattern != "":
+				return nil, nil, fmt.Errorf(
+					"placeholder {%s} of pattern '%s' is not used by any coordinate template, e.g. classifier: \"{{.%s}}\"",
+					names[0], rule.Pattern, names[0])
+			default:
+				return nil, nil, fmt.Errorf(
+					"capture %s of regexp '%s' is not used by any coordinate template, e.g. classifier: \"{{.%s}}\"",
+					names[0], rule.Regexp, names[0])
+			}
+		}
+		if rule.Pattern != "" {
+			expr, err := compileGreedyPattern(rule.Pattern)
+			if err != nil {
+				return nil, nil, err
+			}
+			greedy, err = regexp.Compile(expr)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		var candidates []string
+		for _, s := range sources {
+			prev, ok := claimedBy[s]
+			if !ok {
+				candidates = append(candidates, s)
+				continue
+			}
+			if re == nil || re.MatchString(mvn.relPath(s)) {
+				mvn.infof("warning: source %s is claimed by rule %d and rule %d, using rule %d",
+					mvn.relPath(s), prev+1, i+1, prev+1)
+			} else if mvn.Args.Debug {
+				mvn.infof("source %s matched by rule %d, already claimed by rule %d", mvn.relPath(s), i+1, prev+1)
+			}
+		}
+		if mvn.Args.Debug {
+			fmt.Println("sources found:")
+			spew.Dump(candidates)
+		}
+		if re == nil {
+			if len(candidates) > 1 {
+				return nil, nil, fmt.Errorf(
+					"multiple sources found for %s (%v) but no regexp was defined",
+					rule.Source, candidates)
+			}
+			for _, s := range candidates {
+				a := rule.Artifact
+				a.file = s
+				parsed = append(parsed, a)
+				captures = append(captures, nil)
+				claimedBy[s] = i
+			}
+			continue
+		}
+		for _, s := range candidates {
+			rel, err := filepath.Rel(mvn.workspacePath, s)
+			if err != nil {
+				fmt.Printf("could not make source %s relative to %s\n", s, mvn.workspacePath)
+				return nil, nil, err
+			}
+			matches := re.FindStringSubmatch(rel)
+			if matches == nil {
+				if _, ok := unmatched[s]; !ok {
+					unclaimed = append(unclaimed, s)
+				}
+				unmatched[s] = fmt.Sprintf("regexp '%s'", rule.Regexp)
+				if rule.Pattern != "" {
+					unmatched[s] = fmt.Sprintf("pattern '%s'", rule.Pattern)
+				}
+				continue
+			}
+			if greedy != nil {
+				if other := greedy.FindStringSubmatch(rel); !reflect.DeepEqual(other, matches) {
+					return nil, nil, fmt.Errorf("pattern '%s' is ambiguous for '%s', it matches both %s and %s",
+						rule.Pattern, rel, captureString(re, matches), captureString(greedy, other))
+				}
+			}
+			a := rule.Artifact
+			c := make(map[string]string)
+			for i, name := range re.SubexpNames() {
+				v := matches[i]
+				if name != "" {
+					c[name] = v
+				}
+				if v == "" {
+					continue
+				}
+				switch name {
+				case "version":
+					a.Version = v
+				case "classifier":
+					a.Classifier = v
+				case "artifact":
+					a.ArtifactID = v
+				case "group":
+					a.GroupID = v
+				case "extension":
+					a.Extension = v
+				}
+			}
+			if classifierParts != nil {
+				var parts []string
+				for _, name := range classifierParts {
+					if c[name] != "" {
+						parts = append(parts, c[name])
+					}
+				}
+				a.Classifier = strings.Join(parts, "-")
+			}
+			a.file = s
+			parsed = append(parsed, a)
+			captures = append(captures, c)
+			claimedBy[s] = i
+			if mvn.Args.Debug {
+				if len(rules) > 1 {
+					mvn.infof("source %s claimed by rule %d", mvn.relPath(s), i+1)
+				}
+				fmt.Println("$ parsed artifact")
+				spew.Dump(a)
+			}
+		}
+	}
+	for _, s := range unclaimed {
+		if _, ok := claimedBy[s]; !ok {
+			return nil, nil, fmt.Errorf("%s does not match '%s'", unmatched[s], s)
+		}
+	}
+	if len(parsed) == 0 {
+		return nil, nil, fmt.Errorf("no sources found for %s ", patterns)
+	}
+	return parsed, captures, nil
+}
+
+// ruleRegexp returns the regexp of the rule, compiling its pattern if set.
+func (mvn *Maven) ruleRegexp(rule Rule) (string, error) {
+	if rule.Pattern == "" {
+		return rule.Regexp, nil
+	}
+	if rule.Regexp != "" {
+		return "", fmt.Errorf("both regexp and pattern are set for %s", rule.Source)
+	}
+	expr, err := compilePattern(rule.Pattern)
+	if err != nil {
+		return "", err
+	}
+	if mvn.Args.Debug {
+		mvn.infof("pattern %s compiled to regexp %s", rule.Pattern, expr)
+	}
+	return expr, nil
+}
+
+// unusedCaptures returns the named groups of re which are neither a
+// coordinate nor referenced by a coordinate template of the rule. Files told
+// apart only by such a capture would be deployed with the same coordinates.
+func (mvn *Maven) unusedCaptures(rule Rule, re *regexp.Regexp) []string {
+	var unused []string
+	var templates []string
+	for _, v := range [][2]string{
+		{rule.GroupID, mvn.Artifact.GroupID},
+		{rule.ArtifactID, mvn.Artifact.ArtifactID},
+		{rule.Version, mvn.Artifact.Version},
+		{rule.Classifier, mvn.Artifact.Classifier},
+		{rule.Extension, mvn.Artifact.Extension},
+	} {
+		if v[0] == "" {
+			v[0] = v[1]
+		}
+		templates = append(templates, v[0])
+	}
+	for _, name := range re.SubexpNames() {
+		switch name {
+		case "", "group", "artifact", "version", "classifier", "extension":
+			continue
+		}
+		ref := regexp.MustCompile(`\.` + regexp.QuoteMeta(name) + `\b|"` + regexp.QuoteMeta(name) + `"`)
+		used := false
+		for _, t := range templates {
+			if strings.Contains(t, "{{") && ref.MatchString(t) {
+				used = true
+			}
+		}
+		if !used {
+			unused = append(unused, name)
+		}
+	}
+	return unused
+}
+
+// captureString formats the named captures of a match as name=value pairs.
+func captureString(re *regexp.Regexp, matches []string) string {
+	var pairs []string
+	for i, name := range re.SubexpNames() {
+		if name != "" {
+			pairs = append(pairs, name+"="+matches[i])
+		}
+	}
+	return strings.Join(pairs, " ")
+}
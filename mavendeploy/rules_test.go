@@ -0,0 +1,116 @@
+package mavendeploy
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPrepareRules(t *testing.T) {
+	cli := Rule{
+		Source: Patterns{"multiple-matched/app-{client,gui}-*"},
+		Regexp: `app-(?P<artifact>client|gui)-(?P<classifier>[^-]+-[^-]+)-[0-9.]+\.(?P<extension>zip|tar\.gz)$`,
+		Artifact: Artifact{
+			GroupID: "com.test.cli",
+		},
+	}
+	web := Rule{
+		Source:  Patterns{"multiple-matched/*"},
+		Exclude: Patterns{"*.md"},
+		Regexp:  `app-(?P<artifact>[a-z]+)-linux-amd64-[0-9.]+\.(?P<extension>.+)$`,
+		Artifact: Artifact{
+			GroupID: "com.test.web",
+			Version: "2.0.0",
+		},
+	}
+	for _, v := range []struct {
+		rules    []Rule
+		source   Patterns
+		regexp   string
+		expected map[string]int
+		err      string
+	}{
+		{[]Rule{cli, web}, nil, "", map[string]int{
+			"com.test.cli:client:0.1.4": 4,
+			"com.test.cli:gui:0.1.4":    1,
+			"com.test.web:server:2.0.0": 2,
+		}, ""},
+		{[]Rule{cli}, web.Source, `app-(?P<artifact>[a-z]+)-linux-amd64-[0-9.]+\.(?P<extension>.+)$`, nil,
+			"regexp 'app-(?P<artifact>[a-z]+)-linux-amd64-[0-9.]+\\.(?P<extension>.+)$' does not match"},
+		{[]Rule{cli, {Source: Patterns{"single/release.zip"}}}, nil, "", map[string]int{
+			"com.test.cli:client:0.1.4": 4,
+			"com.test.cli:gui:0.1.4":    1,
+			"com.test.rules:app:0.1.4":  1,
+		}, ""},
+		{[]Rule{cli, {Regexp: ".*"}}, nil, "", nil, "source of rule 2 is required"},
+		{[]Rule{{Source: Patterns{"single/release.zip"}}, {Source: Patterns{"single-matched/*.zip"}}}, nil, "", nil,
+			"sources single/release.zip and single-matched/app-windows-amd64.zip are both deployed as com/test/rules/app/0.1.4/app-0.1.4.zip"},
+	} {
+		mvn := &Maven{
+			Artifact: Artifact{
+				GroupID:    "com.test.rules",
+				ArtifactID: "app",
+				Version:    "0.1.4",
+			},
+			Args: Args{
+				Rules:   v.rules,
+				Source:  v.source,
+				Exclude: Patterns{"*-windows-386-*"},
+				Regexp:  v.regexp,
+			},
+			workspacePath: "test-data/",
+			quiet:         true,
+		}
+		err := mvn.Prepare()
+		if v.err != "" {
+			if err == nil || !strings.Contains(err.Error(), v.err) {
+				t.Fatalf("expected error %q, got %v", v.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		found := make(map[string]int)
+		for k, artifacts := range mvn.artifacts {
+			found[k] = len(artifacts)
+		}
+		if !reflect.DeepEqual(found, v.expected) {
+			t.Fatalf("expected %v, got %v", v.expected, found)
+		}
+	}
+}
+
+func TestPrepareRulesClaimedTwice(t *testing.T) {
+	var log bytes.Buffer
+	mvn := &Maven{
+		Artifact: Artifact{
+			GroupID: "com.test.rules",
+			Version: "0.1.4",
+		},
+		Args: Args{
+			Rules: []Rule{
+				{Source: Patterns{"single/release.zip"}, Artifact: Artifact{ArtifactID: "release"}},
+				{Source: Patterns{"single/*.zip"}, Artifact: Artifact{ArtifactID: "other"}},
+				{Source: Patterns{"single/*.zip"}, Regexp: `\.tar\.gz$`},
+			},
+		},
+		workspacePath: "test-data/",
+		log:           &log,
+	}
+	err := mvn.Prepare()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"com.test.rules:release:0.1.4"}
+	if keys := mvn.artifactKeys(); !reflect.DeepEqual(keys, expected) {
+		t.Fatalf("expected %v, got %v", expected, keys)
+	}
+	if !strings.Contains(log.String(), "warning: source single/release.zip is claimed by rule 1 and rule 2, using rule 1") {
+		t.Fatalf("expected claimed twice warning, got:\n%s", log.String())
+	}
+	if strings.Contains(log.String(), "rule 3") {
+		t.Fatalf("unexpected warning for a rule not matching:\n%s", log.String())
+	}
+}
@@ -0,0 +1,118 @@
+package mavendeploy
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/packet"
+)
+
+// Signer creates ASCII armored detached OpenPGP signatures using the GnuPG
+// private key from the drone-mvn configuration.
+type Signer struct {
+	entity *openpgp.Entity
+}
+
+// PassphraseError is returned when the private key can not be decrypted
+// using the configured passphrase.
+type PassphraseError struct {
+	KeyID string
+}
+
+func (e *PassphraseError) Error() string {
+	return fmt.Sprintf("wrong gpg passphrase for private key %s", e.KeyID)
+}
+
+// NewSigner reads and decrypts the first private key in g.
+func NewSigner(g GPG) (*Signer, error) {
+	entities, err := openpgp.ReadArmoredKeyRing(strings.NewReader(g.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("could not read gpg private key: %v", err)
+	}
+	var entity *openpgp.Entity
+	for _, e := range entities {
+		if e.PrivateKey != nil {
+			entity = e
+			break
+		}
+	}
+	if entity == nil {
+		return nil, fmt.Errorf("could not find gpg private key")
+	}
+	keys := []*packet.PrivateKey{entity.PrivateKey}
+	for _, subkey := range entity.Subkeys {
+		if subkey.PrivateKey != nil {
+			keys = append(keys, subkey.PrivateKey)
+		}
+	}
+	for _, key := range keys {
+		if !key.Encrypted {
+			continue
+		}
+		err := key.Decrypt([]byte(g.Passphrase))
+		if err != nil {
+			return nil, &PassphraseError{KeyID: key.KeyIdString()}
+		}
+	}
+	return &Signer{entity: entity}, nil
+}
+
+// KeyID returns the hex encoded id of the signing key.
+func (s *Signer) KeyID() string {
+	return s.entity.PrivateKey.KeyIdString()
+}
+
+// Sign returns the ASCII armored detached signature of the data read from r.
+func (s *Signer) Sign(r io.Reader) ([]byte, error) {
+	var buf bytes.Buffer
+	err := openpgp.ArmoredDetachSign(&buf, s.entity, r, nil)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// signatureWriter signs everything written to it in the background so that
+// a file can be signed while it is being uploaded.
+type signatureWriter struct {
+	pw     *io.PipeWriter
+	result chan signatureResult
+}
+
+type signatureResult struct {
+	signature []byte
+	err       error
+}
+
+// newSignatureWriter starts signing the data written to the returned writer.
+func (s *Signer) newSignatureWriter() *signatureWriter {
+	pr, pw := io.Pipe()
+	w := &signatureWriter{
+		pw:     pw,
+		result: make(chan signatureResult, 1),
+	}
+	go func() {
+		signature, err := s.Sign(pr)
+		if err != nil {
+			pr.CloseWithError(err)
+		} else {
+			pr.Close()
+		}
+		w.result <- signatureResult{signature, err}
+	}()
+	return w
+}
+
+func (w *signatureWriter) Write(p []byte) (int, error) {
+	return w.pw.Write(p)
+}
+
+// Signature ends the input and returns the signature.
+func (w *signatureWriter) Signature() ([]byte, error) {
+	w.pw.Close()
+	r := <-w.result
+	return r.signature, r.err
+}
@@ -0,0 +1,164 @@
+package mavendeploy
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/openpgp"
+)
+
+func TestSigner(t *testing.T) {
+	signer, err := NewSigner(GPG{
+		PrivateKey: privateKey,
+		Passphrase: "test",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signer.KeyID() != "89E515481F8FA12E" {
+		t.Fatalf("unexpected key id %s", signer.KeyID())
+	}
+	message := []byte("release contents")
+	signature, err := signer.Sign(bytes.NewReader(message))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(signature, []byte("-----BEGIN PGP SIGNATURE-----")) {
+		t.Fatalf("expected armored signature, got %s", signature)
+	}
+	verifySignature(t, message, signature)
+}
+
+func TestSignerInvalidPassphrase(t *testing.T) {
+	_, err := NewSigner(GPG{
+		PrivateKey: privateKey,
+		Passphrase: "WRONG",
+	})
+	if _, ok := err.(*PassphraseError); !ok {
+		t.Fatalf("expected PassphraseError, got %v", err)
+	}
+}
+
+func TestSignerInvalidKey(t *testing.T) {
+	_, err := NewSigner(GPG{
+		PrivateKey: publicKey,
+		Passphrase: "test",
+	})
+	if err == nil {
+		t.Fatal("expected error for public key")
+	}
+}
+
+func TestNativeGPGSign(t *testing.T) {
+	l := LocalTest{
+		t,
+		&Maven{
+			Repository: Repository{
+				Username: "user",
+				Password: "pass",
+			},
+			Artifact: Artifact{
+				GroupID:    "com.test.publishGpg",
+				ArtifactID: "release",
+				Extension:  "zip",
+				Version:    "1.9.3",
+			},
+			GPG: GPG{
+				PrivateKey: privateKey,
+				Passphrase: `test`,
+			},
+			Args: Args{
+				Source:  Patterns{"single/release.zip"},
+				Backend: BackendNative,
+			},
+		}}
+
+	l.Run(func(m *Maven) {
+		err := m.Publish()
+		if err != nil {
+			t.Fatal(err)
+		}
+		l.AssertFiles(
+			"com/test/publishGpg/release/1.9.3/release-1.9.3.pom",
+			"com/test/publishGpg/release/1.9.3/release-1.9.3.pom.asc",
+			"com/test/publishGpg/release/1.9.3/release-1.9.3.pom.asc.md5",
+			"com/test/publishGpg/release/1.9.3/release-1.9.3.pom.asc.sha1",
+			"com/test/publishGpg/release/1.9.3/release-1.9.3.pom.md5",
+			"com/test/publishGpg/release/1.9.3/release-1.9.3.pom.sha1",
+			"com/test/publishGpg/release/1.9.3/release-1.9.3.zip",
+			"com/test/publishGpg/release/1.9.3/release-1.9.3.zip.asc",
+			"com/test/publishGpg/release/1.9.3/release-1.9.3.zip.asc.md5",
+			"com/test/publishGpg/release/1.9.3/release-1.9.3.zip.asc.sha1",
+			"com/test/publishGpg/release/1.9.3/release-1.9.3.zip.md5",
+			"com/test/publishGpg/release/1.9.3/release-1.9.3.zip.sha1",
+			"com/test/publishGpg/release/maven-metadata.xml",
+			"com/test/publishGpg/release/maven-metadata.xml.md5",
+			"com/test/publishGpg/release/maven-metadata.xml.sha1",
+		)
+		tr, err := newTransport(m.Repository, m.httpClient())
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, p := range []string{
+			"com/test/publishGpg/release/1.9.3/release-1.9.3.zip",
+			"com/test/publishGpg/release/1.9.3/release-1.9.3.pom",
+		} {
+			data, err := tr.Get(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			signature, err := tr.Get(p + ".asc")
+			if err != nil {
+				t.Fatal(err)
+			}
+			verifySignature(t, data, signature)
+		}
+	})
+}
+
+func TestNativeGPGSignInvalidPassphrase(t *testing.T) {
+	l := LocalTest{
+		t,
+		&Maven{
+			Repository: Repository{
+				Username: "user",
+				Password: "pass",
+			},
+			Artifact: Artifact{
+				GroupID:    "com.test.publishGpg",
+				ArtifactID: "release",
+				Extension:  "zip",
+				Version:    "1.9.3",
+			},
+			GPG: GPG{
+				PrivateKey: privateKey,
+				Passphrase: `WRONG`,
+			},
+			Args: Args{
+				Source:  Patterns{"single/release.zip"},
+				Backend: BackendNative,
+			},
+		}}
+	l.Run(func(m *Maven) {
+		err := m.Publish()
+		if _, ok := err.(*PassphraseError); !ok {
+			t.Fatalf("expected PassphraseError, got %v", err)
+		}
+		l.AssertNoFiles()
+	})
+}
+
+// verifySignature fails the test unless signature is a valid signature of
+// message made by the test key.
+func verifySignature(t *testing.T, message, signature []byte) {
+	keyring, err := openpgp.ReadArmoredKeyRing(strings.NewReader(publicKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = openpgp.CheckArmoredDetachedSignature(
+		keyring, bytes.NewReader(message), bytes.NewReader(signature))
+	if err != nil {
+		t.Fatalf("invalid signature: %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+package mavendeploy
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+)
+
+// Patterns is a list of workspace relative glob patterns, it can be given as
+// a single pattern string or as a list in json.
+type Patterns []string
+
+func (p *Patterns) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*p = nil
+		if s != "" {
+			*p = Patterns{s}
+		}
+		return nil
+	}
+	var l []string
+	err := json.Unmarshal(data, &l)
+	if err != nil {
+		return err
+	}
+	*p = l
+	return nil
+}
+
+func (p Patterns) String() string {
+	return strings.Join(p, ", ")
+}
+
+// findSources returns the files matching any of the source patterns and
+// none of the exclude patterns. The files are ordered by the first pattern
+// matching them, a file matched by several patterns is only returned once.
+//
+// An exclude pattern without a path separator is also matched against the
+// file name so that e.g. *-debug.* excludes files in any directory.
+func (mvn *Maven) findSources(source, exclude Patterns) ([]string, error) {
+	var sources []string
+	matchedBy := make(map[string]string)
+	for _, pattern := range source {
+		matches, err := mvn.glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range matches {
+			if prev, ok := matchedBy[s]; ok {
+				if mvn.Args.Debug {
+					mvn.infof("source %s matched by %s, already matched by %s", mvn.relPath(s), pattern, prev)
+				}
+				continue
+			}
+			matchedBy[s] = pattern
+			excluded, err := mvn.excludedBy(s, exclude)
+			if err != nil {
+				return nil, err
+			}
+			if excluded != "" {
+				if mvn.Args.Debug {
+					mvn.infof("source %s matched by %s, excluded by %s", mvn.relPath(s), pattern, excluded)
+				}
+				continue
+			}
+			if mvn.Args.Debug {
+				mvn.infof("source %s matched by %s", mvn.relPath(s), pattern)
+			}
+			sources = append(sources, s)
+		}
+	}
+	return sources, nil
+}
+
+// excludedBy returns the exclude pattern matching the source file, if any.
+func (mvn *Maven) excludedBy(source string, exclude Patterns) (string, error) {
+	rel := mvn.relPath(source)
+	for _, pattern := range exclude {
+		name := rel
+		if !strings.Contains(pattern, "/") {
+			name = filepath.Base(rel)
+		}
+		ok, err := filepath.Match(filepath.FromSlash(pattern), name)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			return pattern, nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,80 @@
+package mavendeploy
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPatternsJSON(t *testing.T) {
+	for _, v := range []struct {
+		json     string
+		expected Patterns
+	}{
+		{`"dist/*.zip"`, Patterns{"dist/*.zip"}},
+		{`["dist/*.zip", "dist/*.tar.gz"]`, Patterns{"dist/*.zip", "dist/*.tar.gz"}},
+		{`""`, nil},
+	} {
+		var p Patterns
+		err := json.Unmarshal([]byte(v.json), &p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(p, v.expected) {
+			t.Fatalf("expected %v for %s, got %v", v.expected, v.json, p)
+		}
+	}
+	var p Patterns
+	if err := json.Unmarshal([]byte(`{"source": 1}`), &p); err == nil {
+		t.Fatal("expected error for object")
+	}
+}
+
+func TestFindSources(t *testing.T) {
+	var log bytes.Buffer
+	mvn := &Maven{
+		Args: Args{
+			Debug: true,
+		},
+		workspacePath: "test-data",
+		log:           &log,
+	}
+	sources, err := mvn.findSources(
+		Patterns{
+			"multiple-matched/*.zip",
+			"multiple-matched/*.tar.gz",
+			"multiple-matched/app-client-*",
+			"multiple-matched//app-server-linux-amd64-0.1.4.tar.gz",
+		},
+		Patterns{"*-windows-*", "multiple-matched/app-gui-*"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rel []string
+	for _, v := range sources {
+		rel = append(rel, filepath.ToSlash(mvn.relPath(v)))
+	}
+	expected := []string{
+		"multiple-matched/app-client-darwin-amd64-0.1.4.zip",
+		"multiple-matched/app-client-linux-386-0.1.4.tar.gz",
+		"multiple-matched/app-client-linux-amd64-0.1.4.tar.gz",
+		"multiple-matched/app-server-linux-amd64-0.1.4.tar.gz",
+	}
+	if !reflect.DeepEqual(rel, expected) {
+		t.Fatalf("expected %v, got %v", expected, rel)
+	}
+	for _, line := range []string{
+		"source multiple-matched/app-client-darwin-amd64-0.1.4.zip matched by multiple-matched/*.zip",
+		"source multiple-matched/app-client-windows-386-0.1.4.zip matched by multiple-matched/*.zip, excluded by *-windows-*",
+		"source multiple-matched/app-gui-darwin-amd64-0.1.4.zip matched by multiple-matched/*.zip, excluded by multiple-matched/app-gui-*",
+		"source multiple-matched/app-client-linux-386-0.1.4.tar.gz matched by multiple-matched/app-client-*, already matched by multiple-matched/*.tar.gz",
+	} {
+		if !strings.Contains(log.String(), line) {
+			t.Fatalf("expected %q in debug output:\n%s", line, log.String())
+		}
+	}
+}
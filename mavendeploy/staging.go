@@ -0,0 +1,344 @@
+package mavendeploy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Staging configures deployments through a Nexus 2 staging repository, such
+// as Sonatype OSSRH for Maven Central. A staging repository is opened for the
+// profile, the release versions are uploaded into it and it is then closed,
+// which runs the staging rules, and optionally released. The staging
+// repository is dropped if anything fails.
+//
+// Snapshots are not staged, they are deployed to the snapshot or default
+// repository as usual.
+type Staging struct {
+	URL         string `json:"url"`         // nexus url, e.g. https://oss.sonatype.org
+	ProfileID   string `json:"profile_id"`  // staging profile id
+	Description string `json:"description"` // staging repository description
+	Release     bool   `json:"release"`     // release the repository once it is closed
+	KeepFailed  bool   `json:"keep_failed"` // keep failed repositories for inspection instead of dropping them
+	Timeout     string `json:"timeout"`     // maximum wait for close and release, default 10m
+}
+
+// Staging defaults.
+const (
+	defaultStagingTimeout = 10 * time.Minute
+	stagingPollInterval   = 5 * time.Second
+)
+
+// stagingPlaceholder is the staging repository id shown before a staging
+// repository is opened, e.g. in dry runs.
+const stagingPlaceholder = "{stagingRepositoryId}"
+
+// enabled returns true if releases are deployed through staging.
+func (s Staging) enabled() bool {
+	return s.ProfileID != ""
+}
+
+// staged returns true if version is deployed through a staging repository.
+func (mvn *Maven) staged(version string) bool {
+	return mvn.Staging.enabled() && !isSnapshot(version) && mvn.Args.Backend != BackendCentral
+}
+
+// releaseTransport returns the transport of the release repository staged
+// versions are promoted to. Unlike the staging repository it holds the
+// previously released versions.
+func (mvn *Maven) releaseTransport(version string) (transport, error) {
+	repo, err := mvn.Repository.forVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	return newTransport(repo, mvn.httpClient())
+}
+
+func (s Staging) validate() error {
+	if !s.enabled() {
+		if s.URL != "" {
+			return fmt.Errorf("staging profile_id is %s", errRequiredValue)
+		}
+		return nil
+	}
+	if s.URL == "" {
+		return fmt.Errorf("staging url is %s", errRequiredValue)
+	}
+	if s.Timeout != "" {
+		d, err := time.ParseDuration(s.Timeout)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("staging timeout %s is %s", s.Timeout, errInvalidValue)
+		}
+	}
+	return nil
+}
+
+func (s Staging) timeout() time.Duration {
+	if d, err := time.ParseDuration(s.Timeout); err == nil {
+		return d
+	}
+	return defaultStagingTimeout
+}
+
+// deployURL returns the url of the staging repository id.
+func (s Staging) deployURL(id string) string {
+	return strings.TrimSuffix(s.URL, "/") + "/service/local/staging/deployByRepositoryId/" + id
+}
+
+// StagingError is returned when a staging repository fails to close or
+// release, Failures holds the messages of the failed staging rules.
+type StagingError struct {
+	RepositoryID string
+	Action       string // close or release
+	Failures     []string
+}
+
+func (e *StagingError) Error() string {
+	lines := []string{fmt.Sprintf("staging repository %s failed to %s", e.RepositoryID, e.Action)}
+	for _, v := range e.Failures {
+		lines = append(lines, "  - "+v)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// publishStaged opens a staging repository and deploys the prepared
+// artifacts with releases routed to it, then closes and optionally releases
+// the repository.
+func (mvn *Maven) publishStaged() (err error) {
+	staged := false
+	for _, key := range mvn.artifactKeys() {
+		if !isSnapshot(mvn.artifacts[key][0].Version) {
+			staged = true
+		}
+	}
+	if !staged {
+		return mvn.publishBackend()
+	}
+	c := mvn.stagingClient()
+	id, err := c.start(mvn.Staging.ProfileID, mvn.stagingDescription())
+	if err != nil {
+		return err
+	}
+	mvn.infof("opened staging repository %s", id)
+	mvn.deployment.stagingRepository = id
+	defer func() {
+		if err == nil || mvn.Staging.KeepFailed {
+			return
+		}
+		mvn.infof("dropping staging repository %s", id)
+		if derr := c.bulk("drop", id, mvn.stagingDescription()); derr != nil {
+			mvn.infof("could not drop staging repository %s: %v", id, derr)
+		}
+	}()
+	err = mvn.publishBackend()
+	if err != nil {
+		return err
+	}
+	mvn.infof("closing staging repository %s", id)
+	err = mvn.stagingTransition(c, id, "close", "closed")
+	if err != nil || !mvn.Staging.Release {
+		return err
+	}
+	mvn.infof("releasing staging repository %s", id)
+	return mvn.stagingTransition(c, id, "promote", "released")
+}
+
+// stagingTransition starts the bulk action on the staging repository id and
+// waits until it has reached the state. A repository which isn't
+// transitioning may not have started the action yet so it is only considered
+// failed once the activity of the action has a failure event, the staging
+// rule failures are then returned as a StagingError.
+func (mvn *Maven) stagingTransition(c *stagingClient, id, action, state string) error {
+	err := c.bulk(action, id, mvn.stagingDescription())
+	if err != nil {
+		return err
+	}
+	name := map[string]string{"close": "close", "promote": "release"}[action]
+	timeout := mvn.Staging.timeout()
+	for waited := time.Duration(0); ; waited += stagingPollInterval {
+		r, err := c.repository(id)
+		if err == errNotFound && action == "promote" {
+			// released repositories are dropped automatically
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !r.Transitioning {
+			if r.Type == state {
+				return nil
+			}
+			failed, failures, err := c.failures(id, name)
+			if err != nil {
+				return err
+			}
+			if failed {
+				return &StagingError{RepositoryID: id, Action: name, Failures: failures}
+			}
+		}
+		if waited >= timeout {
+			return fmt.Errorf("timed out after %s waiting for staging repository %s to %s", timeout, id, name)
+		}
+		mvn.pause(stagingPollInterval)
+	}
+}
+
+func (mvn *Maven) stagingDescription() string {
+	if mvn.Staging.Description != "" {
+		return mvn.Staging.Description
+	}
+	if mvn.repo.Owner != "" && mvn.repo.Name != "" {
+		return fmt.Sprintf("%s/%s build %d", mvn.repo.Owner, mvn.repo.Name, mvn.build.Number)
+	}
+	return "drone-mvn"
+}
+
+func (mvn *Maven) stagingClient() *stagingClient {
+	return &stagingClient{
+		base:     strings.TrimSuffix(mvn.Staging.URL, "/") + "/service/local/staging",
+		username: mvn.Repository.Username,
+		password: mvn.Repository.Password,
+		client:   mvn.httpClient(),
+	}
+}
+
+// stagingClient uses the Nexus 2 staging REST API.
+type stagingClient struct {
+	base     string
+	username string
+	password string
+	client   *http.Client
+}
+
+// stagingRepository is the state of a staging repository.
+type stagingRepository struct {
+	RepositoryID  string `json:"repositoryId"`
+	Type          string `json:"type"` // open, closed or released
+	Transitioning bool   `json:"transitioning"`
+}
+
+// stagingActivity is a close or release of a staging repository.
+type stagingActivity struct {
+	Name   string `json:"name"`
+	Events []struct {
+		Name       string `json:"name"`
+		Properties []struct {
+			Name  string `json:"name"`
+			Value string `json:"value"`
+		} `json:"properties"`
+	} `json:"events"`
+}
+
+// start opens a staging repository in the profile and returns its id.
+func (c *stagingClient) start(profileID, description string) (string, error) {
+	var res struct {
+		Data struct {
+			StagedRepositoryID string `json:"stagedRepositoryId"`
+		} `json:"data"`
+	}
+	req := map[string]interface{}{"data": map[string]string{"description": description}}
+	err := c.do("POST", "/profiles/"+profileID+"/start", req, &res)
+	if err != nil {
+		return "", err
+	}
+	if res.Data.StagedRepositoryID == "" {
+		return "", fmt.Errorf("no staging repository opened for profile %s", profileID)
+	}
+	return res.Data.StagedRepositoryID, nil
+}
+
+// bulk runs the close, promote or drop action on the staging repository id.
+func (c *stagingClient) bulk(action, id, description string) error {
+	data := map[string]interface{}{
+		"stagedRepositoryIds": []string{id},
+		"description":         description,
+	}
+	if action == "promote" {
+		data["autoDropAfterRelease"] = true
+	}
+	return c.do("POST", "/bulk/"+action, map[string]interface{}{"data": data}, nil)
+}
+
+// repository returns the state of the staging repository id.
+func (c *stagingClient) repository(id string) (*stagingRepository, error) {
+	var r stagingRepository
+	err := c.do("GET", "/repository/"+id, nil, &r)
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// failures returns true if the last activity with the name, e.g. close, has
+// a failure event, such as ruleFailed or repositoryCloseFailed, along with
+// the failure messages of the staging rules evaluated by it.
+func (c *stagingClient) failures(id, name string) (bool, []string, error) {
+	var activities []stagingActivity
+	err := c.do("GET", "/repository/"+id+"/activity", nil, &activities)
+	if err != nil {
+		return false, nil, err
+	}
+	failed := false
+	var failures []string
+	for i := len(activities) - 1; i >= 0; i-- {
+		if activities[i].Name != name {
+			continue
+		}
+		for _, e := range activities[i].Events {
+			if !strings.HasSuffix(e.Name, "Failed") {
+				continue
+			}
+			failed = true
+			for _, p := range e.Properties {
+				if p.Name == "failureMessage" {
+					failures = append(failures, p.Value)
+				}
+			}
+		}
+		break
+	}
+	return failed, failures, nil
+}
+
+// do sends the request encoded as json and decodes the json response into
+// res unless it is nil.
+func (c *stagingClient) do(method, p string, req, res interface{}) error {
+	var body io.Reader
+	if req != nil {
+		data, err := json.Marshal(req)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	r, err := http.NewRequest(method, c.base+p, body)
+	if err != nil {
+		return err
+	}
+	r.SetBasicAuth(c.username, c.password)
+	r.Header.Set("Accept", "application/json")
+	if body != nil {
+		r.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.client.Do(r)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound && method == "GET" {
+		return errNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return newHTTPError(r, resp)
+	}
+	if res == nil {
+		io.Copy(ioutil.Discard, resp.Body)
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(res)
+}
@@ -0,0 +1,294 @@
+package mavendeploy
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStaging(t *testing.T) {
+	for _, v := range []struct {
+		release  bool
+		pending  int      // polls before the repository starts transitioning
+		failures []string // close rule failures
+		state    string   // expected final state
+		actions  []string
+	}{
+		{false, 0, nil, "closed", []string{"start", "close"}},
+		{false, 2, nil, "closed", []string{"start", "close"}},
+		{true, 0, nil, "released", []string{"start", "close", "promote"}},
+		{true, 1, nil, "released", []string{"start", "close", "promote"}},
+		{true, 0, []string{
+			"Missing Signature: '/com/test/staging/release/1.0.0/release-1.0.0.zip.asc' does not exist for 'release-1.0.0.zip'.",
+			"Missing: no javadoc jar found in folder '/com/test/staging/release/1.0.0'",
+		}, "dropped", []string{"start", "close", "drop"}},
+		{true, 2, []string{
+			"Missing: no javadoc jar found in folder '/com/test/staging/release/1.0.0'",
+		}, "dropped", []string{"start", "close", "drop"}},
+	} {
+		s := newFakeStaging()
+		s.pending = v.pending
+		s.failures = v.failures
+		server := httptest.NewServer(s)
+		var delays []time.Duration
+		mvn := &Maven{
+			Repository: Repository{
+				Username:    "u",
+				Password:    "p",
+				SnapshotURL: server.URL + "/content/repositories/snapshots",
+			},
+			Artifact: Artifact{
+				GroupID:    "com.test.staging",
+				ArtifactID: "release",
+				Version:    "1.0.0",
+			},
+			Args: Args{
+				Source:  Patterns{"single/release.zip"},
+				Backend: BackendNative,
+			},
+			Staging: Staging{
+				URL:       server.URL,
+				ProfileID: "12a34b",
+				Release:   v.release,
+			},
+			workspacePath: "test-data/",
+			quiet:         true,
+			sleep:         func(d time.Duration) { delays = append(delays, d) },
+		}
+		err := mvn.Publish()
+		server.Close()
+		if v.failures == nil && err != nil {
+			t.Fatalf("%+v: %v", v, err)
+		}
+		if v.failures != nil {
+			serr, ok := err.(*StagingError)
+			if !ok || serr.Action != "close" || !reflect.DeepEqual(serr.Failures, v.failures) {
+				t.Fatalf("%+v: expected close rule failures, got %v", v, err)
+			}
+			if !strings.Contains(err.Error(), v.failures[0]) {
+				t.Fatalf("unexpected error message %s", err)
+			}
+		}
+		if s.state != v.state {
+			t.Fatalf("%+v: expected %s staging repository, got %s", v, v.state, s.state)
+		}
+		if !reflect.DeepEqual(s.actions, v.actions) {
+			t.Fatalf("%+v: expected actions %v, got %v", v, v.actions, s.actions)
+		}
+		if len(delays) == 0 || delays[0] != stagingPollInterval {
+			t.Fatalf("%+v: expected polling, got delays %v", v, delays)
+		}
+		files := s.repo.Files()
+		if len(files) != 9 || !strings.HasPrefix(files[0], "/service/local/staging/deployByRepositoryId/comtest-1001/com/test/staging/release/") {
+			t.Fatalf("%+v: expected upload to the staging repository, got %v", v, files)
+		}
+	}
+}
+
+func TestStagingMetadata(t *testing.T) {
+	s := newFakeStaging()
+	s.repo.files["/content/repositories/releases/com/test/staging/release/maven-metadata.xml"] = []byte(`<metadata>
+  <groupId>com.test.staging</groupId>
+  <artifactId>release</artifactId>
+  <versioning>
+    <release>0.9.0</release>
+    <versions>
+      <version>0.9.0</version>
+    </versions>
+  </versioning>
+</metadata>`)
+	server := httptest.NewServer(s)
+	defer server.Close()
+	mvn := &Maven{
+		Repository: Repository{
+			Username:   "u",
+			Password:   "p",
+			ReleaseURL: server.URL + "/content/repositories/releases",
+		},
+		Artifact: Artifact{
+			GroupID:    "com.test.staging",
+			ArtifactID: "release",
+			Version:    "1.0.0",
+		},
+		Args: Args{
+			Source:  Patterns{"single/release.zip"},
+			Backend: BackendNative,
+		},
+		Staging: Staging{
+			URL:       server.URL,
+			ProfileID: "12a34b",
+		},
+		workspacePath: "test-data/",
+		quiet:         true,
+		sleep:         func(time.Duration) {},
+	}
+	err := mvn.Publish()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, ok := s.repo.files["/service/local/staging/deployByRepositoryId/"+fakeStagingID+"/com/test/staging/release/maven-metadata.xml"]
+	if !ok {
+		t.Fatalf("expected staged metadata, got %v", s.repo.Files())
+	}
+	for _, v := range []string{"<version>0.9.0</version>", "<version>1.0.0</version>", "<release>1.0.0</release>"} {
+		if !strings.Contains(string(data), v) {
+			t.Fatalf("expected %s in staged metadata:\n%s", v, data)
+		}
+	}
+}
+
+func TestStagingSnapshot(t *testing.T) {
+	s := newFakeStaging()
+	server := httptest.NewServer(s)
+	defer server.Close()
+	mvn := &Maven{
+		Repository: Repository{
+			Username:    "u",
+			Password:    "p",
+			SnapshotURL: server.URL + "/content/repositories/snapshots",
+		},
+		Artifact: Artifact{
+			GroupID:    "com.test.staging",
+			ArtifactID: "snapshot",
+			Version:    "1.0.0-SNAPSHOT",
+		},
+		Args: Args{
+			Source:  Patterns{"single/release.zip"},
+			Backend: BackendNative,
+		},
+		Staging: Staging{
+			URL:       server.URL,
+			ProfileID: "12a34b",
+		},
+		workspacePath: "test-data/",
+		quiet:         true,
+	}
+	err := mvn.Publish()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.actions) != 0 {
+		t.Fatalf("expected snapshot not to be staged, got %v", s.actions)
+	}
+	files := s.repo.Files()
+	if len(files) == 0 || !strings.HasPrefix(files[0], "/content/repositories/snapshots/") {
+		t.Fatalf("expected upload to the snapshot repository, got %v", files)
+	}
+}
+
+// fakeStaging is a minimal Nexus 2 staging server with a single staging
+// profile. A bulk close fails if failures is set. After a bulk action a
+// repository keeps its previous state for pending polls, as if the action
+// hadn't started yet, and is then transitioning for one poll.
+type fakeStaging struct {
+	mu       sync.Mutex
+	repo     *fakeRepo
+	failures []string
+	pending  int
+	actions  []string
+	state    string // open, closed, released or dropped
+	previous string // state before the last bulk action
+	polls    int    // polls since the last bulk action
+	closing  bool   // the close has started and has activity
+}
+
+func newFakeStaging() *fakeStaging {
+	return &fakeStaging{repo: newFakeRepo()}
+}
+
+const fakeStagingID = "comtest-1001"
+
+func (f *fakeStaging) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.URL.Path, "/service/local/staging/") || strings.Contains(r.URL.Path, "/deployByRepositoryId/") {
+		f.repo.ServeHTTP(w, r)
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if u, p, ok := r.BasicAuth(); !ok || u != "u" || p != "p" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	var req struct {
+		Data struct {
+			StagedRepositoryIDs []string `json:"stagedRepositoryIds"`
+		} `json:"data"`
+	}
+	if r.Method == "POST" {
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+	p := strings.TrimPrefix(r.URL.Path, "/service/local/staging")
+	switch {
+	case r.Method == "POST" && p == "/profiles/12a34b/start":
+		f.actions = append(f.actions, "start")
+		f.state = "open"
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]string{"stagedRepositoryId": fakeStagingID},
+		})
+	case r.Method == "POST" && strings.HasPrefix(p, "/bulk/"):
+		if !reflect.DeepEqual(req.Data.StagedRepositoryIDs, []string{fakeStagingID}) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		action := strings.TrimPrefix(p, "/bulk/")
+		f.actions = append(f.actions, action)
+		f.previous = f.state
+		f.polls = 0
+		switch action {
+		case "close":
+			if f.failures == nil {
+				f.state = "closed"
+			}
+		case "promote":
+			f.state = "released"
+		case "drop":
+			f.state = "dropped"
+		}
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == "GET" && p == "/repository/"+fakeStagingID:
+		f.polls++
+		transitioning := f.polls == f.pending+1
+		if f.polls > f.pending && f.actions[len(f.actions)-1] == "close" {
+			f.closing = true
+		}
+		state := f.state
+		if f.polls <= f.pending+1 {
+			state = f.previous
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"repositoryId":  fakeStagingID,
+			"type":          state,
+			"transitioning": transitioning,
+		})
+	case r.Method == "GET" && p == "/repository/"+fakeStagingID+"/activity":
+		activities := []interface{}{
+			map[string]interface{}{"name": "open", "events": []interface{}{}},
+		}
+		if f.closing {
+			events := []interface{}{map[string]interface{}{"name": "ruleEvaluate"}}
+			if f.failures != nil {
+				properties := []map[string]string{{"name": "typeId", "value": "sources-staging"}}
+				for _, v := range f.failures {
+					properties = append(properties, map[string]string{"name": "failureMessage", "value": v})
+				}
+				events = append(events,
+					map[string]interface{}{"name": "ruleFailed", "properties": properties},
+					map[string]interface{}{"name": "repositoryCloseFailed"})
+			}
+			activities = append(activities, map[string]interface{}{"name": "close", "events": events})
+		}
+		json.NewEncoder(w).Encode(activities)
+	default:
+		http.NotFound(w, r)
+	}
+}
@@ -0,0 +1,112 @@
+package mavendeploy
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/drone/drone-plugin-go/plugin"
+)
+
+// BuildInfo sets the build and repository information available to the
+// coordinate templates.
+func (mvn *Maven) BuildInfo(build plugin.Build, repo plugin.Repo) error {
+	mvn.build = build
+	mvn.repo = repo
+	return nil
+}
+
+// templateFuncs are the functions available to coordinate templates. The
+// value is the last argument so that they can be used in pipelines such as
+// {{ .Tag | trimPrefix "v" }}.
+var templateFuncs = template.FuncMap{
+	"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+	"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+	"replace":    func(old, new, s string) string { return strings.Replace(s, old, new, -1) },
+	"lower":      strings.ToLower,
+	"upper":      strings.ToUpper,
+	"date":       func(layout string, t time.Time) string { return t.UTC().Format(layout) },
+	"default": func(def, s string) string {
+		if s == "" {
+			return def
+		}
+		return s
+	},
+}
+
+// templateData returns the data available to coordinate templates. Named
+// regexp captures are available by their name, e.g. {{.version}}, and in
+// Captures.
+func (mvn *Maven) templateData(captures map[string]string) map[string]interface{} {
+	b := mvn.build
+	var tag string
+	if strings.HasPrefix(b.Ref, "refs/tags/") {
+		tag = strings.TrimPrefix(b.Ref, "refs/tags/")
+	}
+	shortCommit := b.Commit
+	if len(shortCommit) > 8 {
+		shortCommit = shortCommit[:8]
+	}
+	data := map[string]interface{}{
+		"Build":       b,
+		"Repo":        mvn.repo,
+		"Commit":      b.Commit,
+		"ShortCommit": shortCommit,
+		"Branch":      b.Branch,
+		"Tag":         tag,
+		"BuildNumber": b.Number,
+		"Event":       b.Event,
+		"RepoOwner":   mvn.repo.Owner,
+		"RepoName":    mvn.repo.Name,
+		"Created":     time.Unix(b.Created, 0).UTC(),
+		"Started":     time.Unix(b.Started, 0).UTC(),
+		"Finished":    time.Unix(b.Finished, 0).UTC(),
+		"Now":         mvn.now().UTC(),
+		"Captures":    captures,
+	}
+	for k, v := range captures {
+		data[k] = v
+	}
+	return data
+}
+
+// expandTemplates executes the templates in the coordinates of a.
+func (mvn *Maven) expandTemplates(a Artifact, captures map[string]string) (Artifact, error) {
+	data := mvn.templateData(captures)
+	for _, v := range []struct {
+		name  string
+		value *string
+	}{
+		{"group", &a.GroupID},
+		{"artifact", &a.ArtifactID},
+		{"version", &a.Version},
+		{"classifier", &a.Classifier},
+		{"extension", &a.Extension},
+	} {
+		var err error
+		*v.value, err = expandTemplate(v.name, *v.value, data)
+		if err != nil {
+			return a, err
+		}
+	}
+	return a, nil
+}
+
+// expandTemplate executes the template value named name.
+func expandTemplate(name, value string, data map[string]interface{}) (string, error) {
+	if !strings.Contains(value, "{{") {
+		return value, nil
+	}
+	t, err := template.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(value)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s template %q: %v", name, value, err)
+	}
+	var buf bytes.Buffer
+	err = t.Execute(&buf, data)
+	if err != nil {
+		return "", fmt.Errorf("could not execute %s template %q: %v", name, value, err)
+	}
+	return buf.String(), nil
+}
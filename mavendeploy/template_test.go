@@ -0,0 +1,109 @@
+package mavendeploy
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/drone/drone-plugin-go/plugin"
+)
+
+func TestTemplates(t *testing.T) {
+	for _, v := range []struct {
+		build    plugin.Build
+		artifact Artifact
+		regexp   string
+		expected []string
+	}{
+		{
+			plugin.Build{Ref: "refs/tags/v1.2.3"},
+			Artifact{
+				GroupID:    "com.test.{{.RepoOwner}}",
+				ArtifactID: "{{.RepoName}}",
+				Version:    `{{ .Tag | trimPrefix "v" }}`,
+			},
+			"",
+			[]string{"com.test.foo:bar:1.2.3"},
+		},
+		{
+			plugin.Build{Number: 22, Commit: "9f2849d5a1e2", Branch: "master"},
+			Artifact{
+				GroupID:    "com.test.templates",
+				ArtifactID: "{{.RepoName}}",
+				Version:    "1.4.0-{{.Build.Number}}-{{.ShortCommit}}-SNAPSHOT",
+			},
+			"",
+			[]string{"com.test.templates:bar:1.4.0-22-9f2849d5-SNAPSHOT"},
+		},
+		{
+			plugin.Build{Started: 1446292800},
+			Artifact{
+				GroupID:    "com.test.templates",
+				Version:    `{{.ver}}-{{.Started | date "20060102"}}`,
+				Classifier: "{{.os}}-{{.arch}}",
+			},
+			"(?P<artifact>app-[^/-]*)-(?P<os>[^-]*)-(?P<arch>[^-]*)-(?P<ver>.*).(?P<extension>tar.gz|zip|readme)$",
+			[]string{
+				"com.test.templates:app-client:0.1.4-20151031",
+				"com.test.templates:app-gui:0.1.4-20151031",
+				"com.test.templates:app-server:0.1.4-20151031",
+			},
+		},
+	} {
+		mvn := &Maven{
+			Artifact: v.artifact,
+			Args: Args{
+				Source: Patterns{"multiple-matched/app*"},
+				Regexp: v.regexp,
+			},
+			workspacePath: "test-data/",
+			quiet:         true,
+		}
+		if v.regexp == "" {
+			mvn.Args.Source = Patterns{"single/release.zip"}
+		}
+		mvn.BuildInfo(v.build, plugin.Repo{Owner: "foo", Name: "bar"})
+		err := mvn.Prepare()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if keys := mvn.artifactKeys(); !reflect.DeepEqual(keys, v.expected) {
+			t.Fatalf("expected %v, got %v", v.expected, keys)
+		}
+		if v.regexp != "" {
+			for _, a := range mvn.artifacts["com.test.templates:app-client:0.1.4-20151031"] {
+				if !strings.HasPrefix(a.fileName(), "app-client-0.1.4-20151031-"+a.Classifier) {
+					t.Fatalf("unexpected file name %s", a.fileName())
+				}
+				if os := strings.Split(a.Classifier, "-")[0]; os != "darwin" && os != "linux" && os != "windows" {
+					t.Fatalf("unexpected classifier %s", a.Classifier)
+				}
+			}
+		}
+	}
+}
+
+func TestTemplateErrors(t *testing.T) {
+	for _, version := range []string{
+		"{{.Tag",
+		"{{.NoSuchField}}",
+		`{{ .Tag | noSuchFunc }}`,
+	} {
+		mvn := &Maven{
+			Artifact: Artifact{
+				GroupID:    "com.test.templates",
+				ArtifactID: "release",
+				Version:    version,
+			},
+			Args: Args{
+				Source: Patterns{"single/release.zip"},
+			},
+			workspacePath: "test-data/",
+			quiet:         true,
+		}
+		err := mvn.Prepare()
+		if err == nil || !strings.Contains(err.Error(), "version template") {
+			t.Fatalf("expected template error for %s, got %v", version, err)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package mavendeploy
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// transport reads and writes files in a maven repository using repository
+// relative slash separated paths.
+type transport interface {
+	// Get returns the contents of p or errNotFound if it does not exist.
+	Get(p string) ([]byte, error)
+	// Exists returns true if p exists.
+	Exists(p string) (bool, error)
+	// Put writes size bytes read from r to p.
+	Put(p string, r io.Reader, size int64) error
+	// URL returns the full location of p.
+	URL(p string) string
+}
+
+// newTransport returns a transport suitable for the repository URL, http
+// repositories are accessed using client.
+func newTransport(repo Repository, client *http.Client) (transport, error) {
+	u, err := url.Parse(repo.URL)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "file":
+		return &fileTransport{root: filepath.FromSlash(u.Path)}, nil
+	case "http", "https":
+		return &httpTransport{
+			base:     strings.TrimSuffix(repo.URL, "/"),
+			username: repo.Username,
+			password: repo.Password,
+			client:   client,
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported repository url scheme '%s'", u.Scheme)
+}
+
+// fileTransport is a transport for file:// repositories.
+type fileTransport struct {
+	root string
+}
+
+func (f *fileTransport) Get(p string) ([]byte, error) {
+	data, err := ioutil.ReadFile(f.path(p))
+	if os.IsNotExist(err) {
+		return nil, errNotFound
+	}
+	return data, err
+}
+
+func (f *fileTransport) Exists(p string) (bool, error) {
+	_, err := os.Stat(f.path(p))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (f *fileTransport) Put(p string, r io.Reader, size int64) error {
+	filename := f.path(p)
+	err := os.MkdirAll(filepath.Dir(filename), 0755)
+	if err != nil {
+		return err
+	}
+	w, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, r)
+	if err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+func (f *fileTransport) URL(p string) string {
+	return "file://" + filepath.ToSlash(f.path(p))
+}
+
+func (f *fileTransport) path(p string) string {
+	return filepath.Join(f.root, filepath.FromSlash(p))
+}
+
+// httpRoundTripper is shared by the http clients of all repositories and
+// services. A connection which can't be established or doesn't respond in
+// time fails with a network error, which is retried.
+var httpRoundTripper = &http.Transport{
+	Proxy: http.ProxyFromEnvironment,
+	DialContext: (&net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}).DialContext,
+	TLSHandshakeTimeout:   10 * time.Second,
+	ResponseHeaderTimeout: 2 * time.Minute,
+	IdleConnTimeout:       90 * time.Second,
+}
+
+// httpClient returns the client used for all http requests. Each request,
+// including the transfer of its body, is limited to the retry timeout.
+func (mvn *Maven) httpClient() *http.Client {
+	return &http.Client{
+		Transport: httpRoundTripper,
+		Timeout:   mvn.Args.Retry.timeout(),
+	}
+}
+
+// httpTransport is a transport for http:// and https:// repositories which
+// accepts uploads using PUT requests, like Nexus and Artifactory does.
+type httpTransport struct {
+	base     string
+	username string
+	password string
+	client   *http.Client
+}
+
+// httpError is returned when a repository responds with an unexpected status
+// code.
+type httpError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Status     string
+}
+
+func (e *httpError) Error() string {
+	return fmt.Sprintf("%s %s: %s", e.Method, e.URL, e.Status)
+}
+
+func (h *httpTransport) Get(p string) ([]byte, error) {
+	req, err := h.newRequest("GET", p, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := h.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotFound {
+		return nil, errNotFound
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, newHTTPError(req, res)
+	}
+	return ioutil.ReadAll(res.Body)
+}
+
+func (h *httpTransport) Exists(p string) (bool, error) {
+	req, err := h.newRequest("HEAD", p, nil)
+	if err != nil {
+		return false, err
+	}
+	res, err := h.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	res.Body.Close()
+	switch res.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	}
+	return false, newHTTPError(req, res)
+}
+
+func (h *httpTransport) Put(p string, r io.Reader, size int64) error {
+	req, err := h.newRequest("PUT", p, r)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	res, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	io.Copy(ioutil.Discard, res.Body)
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return newHTTPError(req, res)
+	}
+	return nil
+}
+
+func (h *httpTransport) URL(p string) string {
+	return h.base + "/" + p
+}
+
+func (h *httpTransport) newRequest(method, p string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequest(method, h.URL(p), body)
+	if err != nil {
+		return nil, err
+	}
+	if h.username != "" || h.password != "" {
+		req.SetBasicAuth(h.username, h.password)
+	}
+	return req, nil
+}
+
+func newHTTPError(req *http.Request, res *http.Response) error {
+	return &httpError{
+		Method:     req.Method,
+		URL:        req.URL.String(),
+		StatusCode: res.StatusCode,
+		Status:     res.Status,
+	}
+}
@@ -0,0 +1,250 @@
+package mavendeploy
+
+import (
+	"strings"
+	"unicode"
+)
+
+// compareVersions compares two maven versions using the same semantics as
+// maven's ComparableVersion and returns -1, 0 or +1.
+//
+// In short versions are split into numeric and qualifier items on '.', '-'
+// and transitions between digits and letters. Numeric items are compared
+// numerically and well known qualifiers are ordered as
+//
+//	alpha < beta < milestone < rc < snapshot < "" (release) < sp
+//
+// followed by unknown qualifiers in lexical order.
+func compareVersions(a, b string) int {
+	return parseVersion(a).compare(parseVersion(b))
+}
+
+// versionItem is an item of a parsed version. A nil item is used as the
+// padding when comparing lists of different length.
+type versionItem interface {
+	compare(other versionItem) int
+	isNull() bool
+}
+
+// intItem is a numeric version item stored as a decimal string without
+// leading zeros so that numbers of any size can be compared.
+type intItem string
+
+func newIntItem(s string) intItem {
+	s = strings.TrimLeft(s, "0")
+	return intItem(s)
+}
+
+func (i intItem) isNull() bool {
+	return i == ""
+}
+
+func (i intItem) compare(other versionItem) int {
+	switch o := other.(type) {
+	case nil:
+		if i.isNull() {
+			return 0
+		}
+		return 1
+	case intItem:
+		if len(i) != len(o) {
+			return compareInts(len(i), len(o))
+		}
+		return strings.Compare(string(i), string(o))
+	}
+	// numbers are greater than qualifiers and lists
+	return 1
+}
+
+// qualifiers is the ordering of well known qualifiers, the empty string is
+// the release qualifier.
+var qualifiers = []string{"alpha", "beta", "milestone", "rc", "snapshot", "", "sp"}
+
+// qualifierAliases maps qualifier aliases to well known qualifiers.
+var qualifierAliases = map[string]string{
+	"ga":      "",
+	"final":   "",
+	"release": "",
+	"cr":      "rc",
+}
+
+// releaseQualifier is the comparable form of the release qualifier.
+const releaseQualifier = "5"
+
+// stringItem is a qualifier version item.
+type stringItem string
+
+func newStringItem(s string, followedByDigit bool) stringItem {
+	if followedByDigit && len(s) == 1 {
+		switch s {
+		case "a":
+			s = "alpha"
+		case "b":
+			s = "beta"
+		case "m":
+			s = "milestone"
+		}
+	}
+	if alias, ok := qualifierAliases[s]; ok {
+		s = alias
+	}
+	return stringItem(s)
+}
+
+// comparable returns a string which orders the qualifier correctly when
+// compared lexically to other qualifiers.
+func (s stringItem) comparable() string {
+	for i, q := range qualifiers {
+		if string(s) == q {
+			return string('0' + rune(i))
+		}
+	}
+	return string('0'+rune(len(qualifiers))) + "-" + string(s)
+}
+
+func (s stringItem) isNull() bool {
+	return s.comparable() == releaseQualifier
+}
+
+func (s stringItem) compare(other versionItem) int {
+	switch o := other.(type) {
+	case nil:
+		return strings.Compare(s.comparable(), releaseQualifier)
+	case stringItem:
+		return strings.Compare(s.comparable(), o.comparable())
+	}
+	// qualifiers are less than numbers and lists
+	return -1
+}
+
+// listItem is a sub list of version items, created for each '-' separator
+// and digit/letter transition.
+type listItem []versionItem
+
+func (l listItem) isNull() bool {
+	return len(l) == 0
+}
+
+func (l listItem) compare(other versionItem) int {
+	switch o := other.(type) {
+	case nil:
+		if len(l) == 0 {
+			return 0
+		}
+		return l[0].compare(nil)
+	case intItem:
+		return -1
+	case stringItem:
+		return 1
+	case listItem:
+		for i := 0; i < len(l) || i < len(o); i++ {
+			var left, right versionItem
+			if i < len(l) {
+				left = l[i]
+			}
+			if i < len(o) {
+				right = o[i]
+			}
+			var result int
+			if left == nil {
+				if right != nil {
+					result = -right.compare(nil)
+				}
+			} else {
+				result = left.compare(right)
+			}
+			if result != 0 {
+				return result
+			}
+		}
+		return 0
+	}
+	return 0
+}
+
+// normalize removes trailing null items.
+func (l listItem) normalize() listItem {
+	for i := len(l) - 1; i >= 0; i-- {
+		if l[i].isNull() {
+			l = append(l[:i], l[i+1:]...)
+		} else if _, ok := l[i].(listItem); !ok {
+			break
+		}
+	}
+	return l
+}
+
+// parseVersion parses a version into a list of items.
+func parseVersion(version string) listItem {
+	version = strings.ToLower(version)
+
+	// lists are built as a stack of nested lists where each new list is
+	// appended as the last item of its parent once it is complete.
+	stack := []listItem{{}}
+	push := func(item versionItem) {
+		stack[len(stack)-1] = append(stack[len(stack)-1], item)
+	}
+	parseItem := func(isDigit bool, s string) versionItem {
+		if isDigit {
+			return newIntItem(s)
+		}
+		return newStringItem(s, false)
+	}
+
+	isDigit := false
+	start := 0
+	for i, c := range version {
+		switch {
+		case c == '.':
+			if i == start {
+				push(intItem(""))
+			} else {
+				push(parseItem(isDigit, version[start:i]))
+			}
+			start = i + 1
+		case c == '-':
+			if i == start {
+				push(intItem(""))
+			} else {
+				push(parseItem(isDigit, version[start:i]))
+			}
+			start = i + 1
+			stack = append(stack, listItem{})
+		case unicode.IsDigit(c):
+			if !isDigit && i > start {
+				push(newStringItem(version[start:i], true))
+				start = i
+				stack = append(stack, listItem{})
+			}
+			isDigit = true
+		default:
+			if isDigit && i > start {
+				push(parseItem(true, version[start:i]))
+				start = i
+				stack = append(stack, listItem{})
+			}
+			isDigit = false
+		}
+	}
+	if len(version) > start {
+		push(parseItem(isDigit, version[start:]))
+	}
+
+	// unwind the stack, normalizing each list before adding it to its parent
+	for len(stack) > 1 {
+		last := stack[len(stack)-1].normalize()
+		stack = stack[:len(stack)-1]
+		push(last)
+	}
+	return stack[0].normalize()
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,57 @@
+package mavendeploy
+
+import "testing"
+
+func TestCompareVersionsOrdering(t *testing.T) {
+	// each list is in strictly increasing order, taken from the maven
+	// ComparableVersion tests.
+	for _, versions := range [][]string{
+		{
+			"1-alpha2snapshot", "1-alpha2", "1-alpha-123", "1-beta-2",
+			"1-beta123", "1-m2", "1-m11", "1-rc", "1-cr2", "1-rc123",
+			"1-SNAPSHOT", "1", "1-sp", "1-sp2", "1-sp123", "1-abc", "1-def",
+			"1-pom-1", "1-1-snapshot", "1-1", "1-2", "1-123",
+		},
+		{
+			"2.0", "2-1", "2.0.a", "2.0.0.a", "2.0.2", "2.0.123", "2.1.0",
+			"2.1-a", "2.1b", "2.1-c", "2.1-1", "2.1.0.1", "2.2", "2.123",
+			"11.a2", "11.a11", "11.b2", "11.b11", "11.m2", "11.m11", "11",
+			"11.a", "11b", "11c", "11m",
+		},
+		{
+			"0.1.2-SNAPSHOT", "0.1.3", "0.1.4", "0.1.10", "0.10.0",
+			"20151031.1", "99999999999999999999.1",
+		},
+	} {
+		for i := range versions {
+			for j := range versions {
+				expected := compareInts(i, j)
+				if c := compareVersions(versions[i], versions[j]); c != expected {
+					t.Errorf("compareVersions(%s, %s) = %d, expected %d",
+						versions[i], versions[j], c, expected)
+				}
+			}
+		}
+	}
+}
+
+func TestCompareVersionsEqual(t *testing.T) {
+	for _, v := range [][2]string{
+		{"1", "1.0"},
+		{"1", "1.0.0"},
+		{"1", "1-ga"},
+		{"1", "1-final"},
+		{"1", "1-release"},
+		{"1a", "1-a"},
+		{"1a", "1.0-a"},
+		{"1x", "1-x"},
+		{"1cr", "1rc"},
+		{"1m3", "1milestone3"},
+		{"1-SNAPSHOT", "1-snapshot"},
+		{"1.0.01", "1.0.1"},
+	} {
+		if c := compareVersions(v[0], v[1]); c != 0 {
+			t.Errorf("compareVersions(%s, %s) = %d, expected 0", v[0], v[1], c)
+		}
+	}
+}